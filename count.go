@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"tetris_optimizer/internal/solver"
+)
+
+const countUsage = "Usage: go run main.go count [flags] <filename|->"
+
+// runCount prints how many packings fit the puzzle on its smallest square
+// and returns the process exit code.
+func runCount(args []string) int {
+	flags := newFlagSet("count", countUsage)
+	timeout := flags.Duration("timeout", 0, "give up counting after this long, e.g. 30s (0 means no limit)")
+	identical := flags.Bool("identical", false, "count packings that differ only by swapping identical pieces once")
+	symmetry := flags.Bool("symmetry", false, "count packings that differ only by rotating or reflecting the board once")
+	poly := flags.Bool("poly", false, "read pieces of any size from square blocks instead of tetrominoes")
+	sandbox := flags.String("sandbox", "", "only read .txt puzzles from inside this directory, e.g. testfiles")
+	verbose := flags.Bool("verbose", false, "explain errors after the ERROR line")
+	if err := flags.Parse(args); err != nil {
+		return parseExitCode(err)
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, countUsage)
+		return exitUsage
+	}
+
+	var dedup solver.Dedup
+	if *identical {
+		dedup |= solver.DedupIdenticalPieces
+	}
+	if *symmetry {
+		dedup |= solver.DedupBoardSymmetry
+	}
+
+	ctx, cancel := timeoutContext(*timeout)
+	defer cancel()
+
+	var opts []solver.Option
+	if *poly {
+		opts = append(opts, solver.WithPolyominoes())
+	}
+	if *sandbox != "" {
+		opts = append(opts, solver.WithSandbox(*sandbox))
+	}
+	tetrominos, err := readPuzzle(flags.Arg(0), opts)
+	if err != nil {
+		return reportError(err, *verbose)
+	}
+	count, err := solver.CountSolutionsContext(ctx, tetrominos, solver.WithDedup(dedup))
+	if err != nil {
+		return reportError(err, *verbose)
+	}
+
+	fmt.Println(count)
+	return exitOK
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"tetris_optimizer/internal/solver"
+	"time"
+	"unicode/utf8"
+)
+
+const generateUsage = "Usage: go run main.go generate [flags]"
+
+// runGenerate prints a random puzzle and returns the process exit code.
+func runGenerate(args []string) int {
+	flags := newFlagSet("generate", generateUsage)
+	pieces := flags.Int("n", 8, "number of pieces, from 1 to 26")
+	seed := flags.Int64("seed", 0, "random seed for a reproducible puzzle (0 picks one from the clock and prints it on stderr)")
+	shapes := flags.String("shapes", "", "relative weight of each shape, e.g. I=2,O=1,T=1 (default all of "+solver.ShapeNames+" equally)")
+	empty := flags.Int("empty", -1, "require exactly this many empty cells in the optimal solution (-1 means any)")
+	attempts := flags.Int("attempts", 0, "puzzles to try for -empty before giving up (0 means 1000)")
+	timeout := flags.Duration("timeout", 0, "give up after this long, e.g. 30s (0 means no limit)")
+	verbose := flags.Bool("verbose", false, "explain errors after the ERROR line")
+	if err := flags.Parse(args); err != nil {
+		return parseExitCode(err)
+	}
+	if flags.NArg() != 0 {
+		fmt.Fprintln(os.Stderr, generateUsage)
+		return exitUsage
+	}
+
+	opts := solver.GenerateOptions{Pieces: *pieces, Seed: *seed, MaxAttempts: *attempts}
+	if *shapes != "" {
+		weights, err := parseWeights(*shapes)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitUsage
+		}
+		opts.Weights = weights
+	}
+	if *empty >= 0 {
+		opts.Empty = empty
+	}
+	if opts.Seed == 0 {
+		opts.Seed = time.Now().UnixNano()
+		fmt.Fprintln(os.Stderr, "seed", opts.Seed)
+	}
+
+	ctx, cancel := timeoutContext(*timeout)
+	defer cancel()
+
+	puzzle, err := solver.GenerateContext(ctx, opts)
+	if err != nil {
+		return reportError(err, *verbose)
+	}
+	fmt.Print(puzzle)
+	return exitOK
+}
+
+// parseWeights reads shape weights written as NAME=WEIGHT pairs separated
+// by commas.
+func parseWeights(s string) (map[rune]float64, error) {
+	weights := make(map[rune]float64)
+	for _, pair := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || utf8.RuneCountInString(name) != 1 {
+			return nil, fmt.Errorf("invalid shape weight %q, want NAME=WEIGHT", pair)
+		}
+		w, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid weight for shape %s: %v", name, err)
+		}
+		r, _ := utf8.DecodeRuneInString(strings.ToUpper(name))
+		weights[r] = w
+	}
+	return weights, nil
+}
@@ -0,0 +1,103 @@
+package solver
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// Box-drawing junctions indexed by the arms they have: up, down, left and
+// right in bits 0 to 3.
+var boxJunctions = [16]string{
+	" ", "│", "│", "│",
+	"─", "┘", "┐", "┤",
+	"─", "└", "┌", "├",
+	"─", "┴", "┬", "┼",
+}
+
+const (
+	junctionUp = 1 << iota
+	junctionDown
+	junctionLeft
+	junctionRight
+)
+
+// RenderANSI writes the board for a colour terminal. Each piece's letter
+// sits on the background colour of PieceColor, and box-drawing lines run
+// around the board and between pieces.
+func RenderANSI(w io.Writer, b *Board) error {
+	at := func(x, y int) rune {
+		if y < 0 || y >= b.Height || x < 0 || x >= b.Width {
+			return -1
+		}
+		return b.Grid[y][x]
+	}
+	// Edges between two cells are drawn where the cells differ; the board
+	// edge always differs from the outside.
+	vertical := func(x, y int) bool { return at(x-1, y) != at(x, y) }
+	horizontal := func(x, y int) bool { return at(x, y-1) != at(x, y) }
+
+	bw := bufio.NewWriter(w)
+	for y := 0; y <= b.Height; y++ {
+		for x := 0; x <= b.Width; x++ {
+			arms := 0
+			if y > 0 && vertical(x, y-1) {
+				arms |= junctionUp
+			}
+			if y < b.Height && vertical(x, y) {
+				arms |= junctionDown
+			}
+			if x > 0 && horizontal(x-1, y) {
+				arms |= junctionLeft
+			}
+			if x < b.Width && horizontal(x, y) {
+				arms |= junctionRight
+			}
+			if arms == 0 {
+				paint(bw, at(x, y), " ")
+			} else {
+				bw.WriteString(boxJunctions[arms])
+			}
+			if x < b.Width {
+				if arms&junctionRight != 0 {
+					bw.WriteString("───")
+				} else {
+					paint(bw, at(x, y), "   ")
+				}
+			}
+		}
+		bw.WriteByte('\n')
+		if y == b.Height {
+			break
+		}
+
+		for x := 0; x <= b.Width; x++ {
+			if vertical(x, y) {
+				bw.WriteString("│")
+			} else {
+				paint(bw, at(x, y), " ")
+			}
+			if x == b.Width {
+				continue
+			}
+			if r := b.Grid[y][x]; r != 0 {
+				paint(bw, r, " "+string(r)+" ")
+			} else {
+				bw.WriteString(" . ")
+			}
+		}
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// paint writes s in black on the colour of the piece r, or plainly when r
+// is empty. Gaps inside a piece are painted so it reads as one block.
+func paint(w *bufio.Writer, r rune, s string) {
+	if r <= 0 {
+		w.WriteString(s)
+		return
+	}
+	c := PieceColor(int(r - 'A'))
+	fmt.Fprintf(w, "\x1b[30;48;2;%d;%d;%dm%s\x1b[0m", c.R, c.G, c.B, s)
+}
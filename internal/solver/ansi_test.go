@@ -0,0 +1,43 @@
+package solver
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+func TestRenderANSI(t *testing.T) {
+	board := NewRectBoard(3, 2)
+	board.Place(makeTetromino('A', []Point{{0, 0}, {1, 0}, {1, 1}}), 0, 0)
+	board.Place(makeTetromino('B', []Point{{0, 0}}), 2, 1)
+
+	var buf bytes.Buffer
+	if err := RenderANSI(&buf, board); err != nil {
+		t.Fatalf("RenderANSI() error = %v", err)
+	}
+
+	want := strings.Join([]string{
+		"┌───────┬───┐",
+		"│ A   A │ . │",
+		"├───┐   ├───┤",
+		"│ . │ A │ B │",
+		"└───┴───┴───┘",
+		"",
+	}, "\n")
+	if got := ansiEscape.ReplaceAllString(buf.String(), ""); got != want {
+		t.Errorf("RenderANSI() without colours =\n%s\nwant\n%s", got, want)
+	}
+
+	c := PieceColor(1)
+	if colour := fmt.Sprintf("\x1b[30;48;2;%d;%d;%dm B \x1b[0m", c.R, c.G, c.B); !strings.Contains(buf.String(), colour) {
+		t.Errorf("RenderANSI() = %q; want B on its colour %q", buf.String(), colour)
+	}
+	a := PieceColor(0)
+	if gap := fmt.Sprintf("\x1b[0m\x1b[30;48;2;%d;%d;%dm \x1b[0m", a.R, a.G, a.B); !strings.Contains(buf.String(), gap) {
+		t.Errorf("RenderANSI() = %q; want the gap inside A painted %q", buf.String(), gap)
+	}
+}
@@ -2,30 +2,63 @@ package solver
 
 import "bytes"
 
+// maxBoardSize is the widest board a row bit mask can hold.
+const maxBoardSize = 64
+
+// MaxWidth is the widest board WithWidth accepts.
+const MaxWidth = maxBoardSize
+
 // Board represents the Tetris game board.
 type Board struct {
 	Grid   [][]rune
-	Size   int // Square board, so width = height
+	Size   int // Side of a square board; zero when width != height
+	Width  int
+	Height int
 	Placed int
+
+	rows []uint64 // occupied cells, one bit per column
 }
 
 // NewBoard creates a new square board of given size.
 func NewBoard(size int) *Board {
-	if size <= 0 {
+	return NewRectBoard(size, size)
+}
+
+// NewRectBoard creates a new board of the given width and height.
+func NewRectBoard(width, height int) *Board {
+	if width <= 0 || height <= 0 || width > maxBoardSize {
 		return nil
 	}
-	grid := make([][]rune, size)
+	grid := make([][]rune, height)
 	for i := range grid {
-		grid[i] = make([]rune, size)
+		grid[i] = make([]rune, width)
+	}
+	b := &Board{Grid: grid, Width: width, Height: height, rows: make([]uint64, height)}
+	if width == height {
+		b.Size = width
 	}
-	return &Board{Grid: grid, Size: size}
+	return b
+}
+
+// Clone returns an independent copy of the board.
+func (b *Board) Clone() *Board {
+	c := NewRectBoard(b.Width, b.Height)
+	for y := range b.Grid {
+		copy(c.Grid[y], b.Grid[y])
+	}
+	copy(c.rows, b.rows)
+	c.Placed = b.Placed
+	return c
 }
 
 // CanPlace checks if a tetromino can be placed at position (x, y).
 func (b *Board) CanPlace(t *Tetromino, x, y int) bool {
-	for _, p := range t.Points {
-		nx, ny := x+p.X, y+p.Y
-		if nx < 0 || ny < 0 || nx >= b.Size || ny >= b.Size || b.Grid[ny][nx] != 0 {
+	m := t.mask()
+	if x < 0 || y < 0 || x+m.width > b.Width || y+len(m.rows) > b.Height {
+		return false
+	}
+	for i, row := range m.rows {
+		if b.rows[y+i]&(row<<uint(x)) != 0 {
 			return false
 		}
 	}
@@ -34,6 +67,9 @@ func (b *Board) CanPlace(t *Tetromino, x, y int) bool {
 
 // Place places a tetromino at position (x, y).
 func (b *Board) Place(t *Tetromino, x, y int) {
+	for i, row := range t.mask().rows {
+		b.rows[y+i] |= row << uint(x)
+	}
 	for _, p := range t.Points {
 		b.Grid[y+p.Y][x+p.X] = t.Letter
 	}
@@ -42,6 +78,9 @@ func (b *Board) Place(t *Tetromino, x, y int) {
 
 // Remove removes a tetromino from position (x, y).
 func (b *Board) Remove(t *Tetromino, x, y int) {
+	for i, row := range t.mask().rows {
+		b.rows[y+i] &^= row << uint(x)
+	}
 	for _, p := range t.Points {
 		b.Grid[y+p.Y][x+p.X] = 0
 	}
@@ -51,15 +90,15 @@ func (b *Board) Remove(t *Tetromino, x, y int) {
 // String converts the board to a string representation.
 func (b *Board) String() string {
 	var buf bytes.Buffer
-	for y := 0; y < b.Size; y++ {
-		for x := 0; x < b.Size; x++ {
+	for y := 0; y < b.Height; y++ {
+		for x := 0; x < b.Width; x++ {
 			if b.Grid[y][x] == 0 {
 				buf.WriteByte('.')
 			} else {
 				buf.WriteRune(b.Grid[y][x])
 			}
 		}
-		if y < b.Size-1 {
+		if y < b.Height-1 {
 			buf.WriteByte('\n')
 		}
 	}
@@ -89,3 +89,81 @@ func TestString(t *testing.T) {
 		t.Errorf("Expected empty board:\n%s\nGot:\n%s", expectedEmpty, board.String())
 	}
 }
+
+func TestNewBoardTooLarge(t *testing.T) {
+	if b := NewBoard(maxBoardSize); b == nil {
+		t.Errorf("Expected board of size %d", maxBoardSize)
+	}
+	if b := NewBoard(maxBoardSize + 1); b != nil {
+		t.Errorf("Expected nil board for size %d", maxBoardSize+1)
+	}
+}
+
+func TestCanPlaceWideBoard(t *testing.T) {
+	board := NewBoard(maxBoardSize)
+	bar := makeTetromino('D', []Point{{0, 0}, {1, 0}, {2, 0}, {3, 0}})
+
+	if !board.CanPlace(bar, maxBoardSize-4, 0) {
+		t.Error("Expected valid placement against the right edge")
+	}
+	if board.CanPlace(bar, maxBoardSize-3, 0) {
+		t.Error("Expected invalid placement past the right edge")
+	}
+
+	board.Place(bar, maxBoardSize-4, 0)
+	if board.CanPlace(bar, maxBoardSize-5, 0) {
+		t.Error("Expected invalid placement (overlap at the right edge)")
+	}
+	board.Remove(bar, maxBoardSize-4, 0)
+	if !board.CanPlace(bar, maxBoardSize-5, 0) {
+		t.Error("Expected valid placement after removal")
+	}
+}
+
+func TestClone(t *testing.T) {
+	board := NewBoard(3)
+	tetromino := makeTetromino('E', []Point{{0, 0}, {1, 0}})
+	board.Place(tetromino, 0, 0)
+
+	clone := board.Clone()
+	if clone.String() != board.String() || clone.Placed != board.Placed {
+		t.Errorf("Expected clone to match board:\n%s\nGot:\n%s", board.String(), clone.String())
+	}
+
+	clone.Place(tetromino, 0, 1)
+	if board.Grid[1][0] != 0 || !board.CanPlace(tetromino, 0, 1) {
+		t.Error("Placing on the clone changed the original board")
+	}
+	if clone.CanPlace(tetromino, 0, 0) {
+		t.Error("Expected clone to keep the original occupancy")
+	}
+}
+
+func TestNewRectBoard(t *testing.T) {
+	b := NewRectBoard(5, 2)
+	if b == nil {
+		t.Fatal("Expected non-nil board")
+	}
+	if b.Width != 5 || b.Height != 2 || b.Size != 0 {
+		t.Errorf("Expected 5x2 board with Size 0, got %dx%d with Size %d", b.Width, b.Height, b.Size)
+	}
+	if len(b.Grid) != 2 || len(b.Grid[0]) != 5 {
+		t.Error("Board grid not properly initialized")
+	}
+
+	bar := makeTetromino('F', []Point{{0, 0}, {1, 0}, {2, 0}, {3, 0}})
+	if !b.CanPlace(bar, 1, 1) {
+		t.Error("Expected valid placement at (1,1)")
+	}
+	if b.CanPlace(bar, 2, 0) {
+		t.Error("Expected invalid placement (past the right edge)")
+	}
+	b.Place(bar, 1, 1)
+	if want := ".....\n.FFFF"; b.String() != want {
+		t.Errorf("Expected board string:\n%s\nGot:\n%s", want, b.String())
+	}
+
+	if NewRectBoard(0, 3) != nil || NewRectBoard(3, 0) != nil || NewRectBoard(maxBoardSize+1, 1) != nil {
+		t.Error("Expected nil board for invalid dimensions")
+	}
+}
@@ -0,0 +1,212 @@
+package solver
+
+import (
+	"context"
+	"math/bits"
+)
+
+// dlxMatrix is a sparse exact-cover matrix linked in Knuth's dancing links
+// layout. Node 0 is the root, nodes 1..columns are column headers and the
+// rest are the ones of each row.
+type dlxMatrix struct {
+	left, right, up, down []int
+	col, row              []int
+	size                  []int
+	rows                  []placement
+	solution              []int
+	stats                 Stats
+
+	// The chosen rows are kept on board so regions no piece can fill are
+	// pruned as in the backtracker.
+	board   *Board
+	pieces  int
+	regions *regionScanner
+	slack   int // empty cells the board can afford to leave
+
+	// Identical pieces are interchangeable, so each copy only takes a row
+	// after the position of the copy before it.
+	previous []int // index of the previous identical piece, or -1
+	anchors  []int // y*width+x of the first cell of each placed piece
+}
+
+// newDLXMatrix builds the exact-cover matrix for placing pieces on the free
+// cells of board, with one row for every orientation and position of each
+// piece. Each piece is a primary column that must be covered exactly once;
+// each cell is a secondary column that may stay empty.
+func newDLXMatrix(board *Board, orientations [][]*Tetromino) *dlxMatrix {
+	primary := len(orientations)
+	columns := primary + board.Width*board.Height
+	m := &dlxMatrix{board: board, pieces: primary, previous: make([]int, primary), anchors: make([]int, primary)}
+	first := make([]*Tetromino, primary)
+	cells := 0
+	for i, piece := range orientations {
+		first[i] = piece[0]
+		cells += len(piece[0].Points)
+	}
+	for _, g := range groupRepetitiveTetrominos(first) {
+		m.previous[g.indices[0]] = -1
+		for k := 1; k < len(g.indices); k++ {
+			m.previous[g.indices[k]] = g.indices[k-1]
+		}
+	}
+	m.regions = newRegionScanner(board.Height, first)
+	m.slack = board.Width*board.Height - cells
+	for _, row := range board.rows {
+		m.slack -= bits.OnesCount64(row)
+	}
+	for i := 0; i <= columns; i++ {
+		m.appendNode(i, -1)
+		m.up[i], m.down[i] = i, i
+		m.left[i], m.right[i] = i, i
+	}
+
+	// Only piece columns are linked into the header list, so the search
+	// never has to choose a cell.
+	for c := 1; c <= primary; c++ {
+		m.left[c] = c - 1
+		m.right[c] = (c + 1) % (primary + 1)
+	}
+	m.left[0] = primary
+	m.right[0] = 1 % (primary + 1)
+
+	// Rows are added in the same order the backtracker tries positions, so
+	// both engines agree on the first solution.
+	for i, piece := range orientations {
+		for _, pl := range placementsOf(board, piece) {
+			cells := make([]int, 0, len(pl.t.Points)+1)
+			cells = append(cells, i+1)
+			for _, p := range pl.t.Points {
+				cells = append(cells, primary+1+(pl.y+p.Y)*board.Width+pl.x+p.X)
+			}
+			m.addRow(cells, pl)
+		}
+	}
+	return m
+}
+
+func (m *dlxMatrix) appendNode(col, row int) int {
+	m.left = append(m.left, 0)
+	m.right = append(m.right, 0)
+	m.up = append(m.up, 0)
+	m.down = append(m.down, 0)
+	m.col = append(m.col, col)
+	m.row = append(m.row, row)
+	m.size = append(m.size, 0)
+	return len(m.col) - 1
+}
+
+// addRow links a new row covering the given columns.
+func (m *dlxMatrix) addRow(columns []int, r placement) {
+	id := len(m.rows)
+	m.rows = append(m.rows, r)
+	first := -1
+	for _, c := range columns {
+		n := m.appendNode(c, id)
+		m.up[n] = m.up[c]
+		m.down[n] = c
+		m.down[m.up[c]] = n
+		m.up[c] = n
+		m.size[c]++
+		if first < 0 {
+			first = n
+			m.left[n], m.right[n] = n, n
+			continue
+		}
+		m.left[n] = m.left[first]
+		m.right[n] = first
+		m.right[m.left[first]] = n
+		m.left[first] = n
+	}
+}
+
+func (m *dlxMatrix) cover(c int) {
+	m.right[m.left[c]] = m.right[c]
+	m.left[m.right[c]] = m.left[c]
+	for i := m.down[c]; i != c; i = m.down[i] {
+		for j := m.right[i]; j != i; j = m.right[j] {
+			m.down[m.up[j]] = m.down[j]
+			m.up[m.down[j]] = m.up[j]
+			m.size[m.col[j]]--
+		}
+	}
+}
+
+func (m *dlxMatrix) uncover(c int) {
+	for i := m.up[c]; i != c; i = m.up[i] {
+		for j := m.left[i]; j != i; j = m.left[j] {
+			m.size[m.col[j]]++
+			m.down[m.up[j]] = j
+			m.up[m.down[j]] = j
+		}
+	}
+	m.right[m.left[c]] = c
+	m.left[m.right[c]] = c
+}
+
+// search runs Algorithm X, always branching on the first uncovered piece so
+// pieces are placed in their given order. Like the backtracker it skips a
+// row that leaves pockets the remaining pieces can never fill. It abandons
+// the search once ctx is done.
+func (m *dlxMatrix) search(ctx context.Context) bool {
+	c := m.right[0]
+	if c == 0 {
+		return true
+	}
+	if ctx.Err() != nil {
+		return false
+	}
+	// A piece with nowhere left to go dooms this branch.
+	for j := c; j != 0; j = m.right[j] {
+		if m.size[j] == 0 {
+			m.stats.Pruned++
+			return false
+		}
+	}
+
+	piece := c - 1
+	start := 0
+	if p := m.previous[piece]; p >= 0 {
+		start = m.anchors[p] + 1
+	}
+	m.cover(c)
+	for r := m.down[c]; r != c; r = m.down[r] {
+		pl := m.rows[m.row[r]]
+		anchor := pl.y*m.board.Width + pl.x + pl.t.lead()
+		if anchor < start {
+			continue
+		}
+		m.anchors[piece] = anchor
+		for j := m.right[r]; j != r; j = m.right[j] {
+			m.cover(m.col[j])
+		}
+		m.board.Place(pl.t, pl.x, pl.y)
+		m.solution = append(m.solution, m.row[r])
+		m.stats.enter(len(m.solution))
+		m.stats.Places++
+		if len(m.solution) == m.pieces || m.regions.wastedCells(m.board, m.slack) <= m.slack {
+			if m.search(ctx) {
+				return true
+			}
+		} else {
+			m.stats.Pruned++
+		}
+		m.solution = m.solution[:len(m.solution)-1]
+		m.board.Remove(pl.t, pl.x, pl.y)
+		m.stats.Removes++
+		m.stats.Backtracks++
+		for j := m.left[r]; j != r; j = m.left[j] {
+			m.uncover(m.col[j])
+		}
+	}
+	m.uncover(c)
+	return false
+}
+
+// solveDLX places one orientation of every piece on board using dancing
+// links, adding the work it did to stats when that is not nil.
+func solveDLX(ctx context.Context, board *Board, orientations [][]*Tetromino, stats *Stats) bool {
+	m := newDLXMatrix(board, orientations)
+	found := m.search(ctx)
+	stats.add(m.stats)
+	return found
+}
@@ -0,0 +1,96 @@
+package solver
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSolveDLXMatchesBacktracking(t *testing.T) {
+	shapes := [][]string{
+		{"...#", "...#", "...#", "...#"},
+		{"....", "....", "....", "####"},
+		{".###", "...#", "....", "...."},
+		{"....", "..##", ".##.", "...."},
+		{"....", ".##.", ".##.", "...."},
+		{"....", "....", "##..", ".##."},
+		{"##..", ".#..", ".#..", "...."},
+		{"....", "###.", ".#..", "...."},
+	}
+
+	tests := []struct {
+		name   string
+		pieces int
+	}{
+		{"OnePiece", 1},
+		{"FourPieces", 4},
+		{"EightPieces", 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tetrominos []*Tetromino
+			for i, shape := range shapes[:tt.pieces] {
+				tetromino, err := createTestTetromino(shape, i)
+				if err != nil {
+					t.Fatalf("createTestTetromino() error = %v", err)
+				}
+				tetrominos = append(tetrominos, tetromino)
+			}
+
+			want, err := generalSquareSolver(context.Background(), tetrominos)
+			if err != nil {
+				t.Fatalf("generalSquareSolver() error = %v", err)
+			}
+			got, err := generalSquareSolver(context.Background(), tetrominos, WithAlgorithm(DancingLinks))
+			if err != nil {
+				t.Fatalf("generalSquareSolver(DancingLinks) error = %v", err)
+			}
+			if got.String() != want.String() {
+				t.Errorf("generalSquareSolver(DancingLinks) = %q; want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestSolveDLXRespectsOccupiedCells(t *testing.T) {
+	square, err := createTestTetromino([]string{
+		"##..",
+		"##..",
+		"....",
+		"....",
+	}, 0)
+	if err != nil {
+		t.Fatalf("ERROR")
+	}
+
+	board := NewBoard(3)
+	board.Place(makeTetromino('X', []Point{{0, 0}, {1, 0}, {2, 0}}), 0, 0)
+	if !solveDLX(context.Background(), board, [][]*Tetromino{{square}}, nil) {
+		t.Fatal("solveDLX() = false; want true")
+	}
+	want := "XXX\nAA.\nAA."
+	if got := board.String(); got != want {
+		t.Errorf("solveDLX() board = %q; want %q", got, want)
+	}
+
+	full := NewBoard(2)
+	full.Place(makeTetromino('X', []Point{{0, 0}}), 0, 0)
+	if solveDLX(context.Background(), full, [][]*Tetromino{{square}}, nil) {
+		t.Error("solveDLX() = true on a board with no room; want false")
+	}
+}
+
+func TestSolveDLXPrunes(t *testing.T) {
+	// Both engines skip dead regions and reorderings of identical pieces,
+	// so they search the same tree.
+	var backtracking, dlx Stats
+	if _, err := SolveBoard(repeatedTs(t), WithMinimalSize(), WithStats(&backtracking)); err != nil {
+		t.Fatalf("SolveBoard() error = %v", err)
+	}
+	if _, err := SolveBoard(repeatedTs(t), WithMinimalSize(), WithAlgorithm(DancingLinks), WithStats(&dlx)); err != nil {
+		t.Fatalf("SolveBoard(DancingLinks) error = %v", err)
+	}
+	if dlx.Pruned == 0 || dlx.Nodes != backtracking.Nodes {
+		t.Errorf("DancingLinks searched %d nodes, pruning %d; want %d nodes like Backtracking, with pruning", dlx.Nodes, dlx.Pruned, backtracking.Nodes)
+	}
+}
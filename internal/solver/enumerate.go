@@ -0,0 +1,138 @@
+package solver
+
+import (
+	"context"
+	"math"
+	"strings"
+)
+
+// EnumerateSolutions calls fn with every packing of tetrominos on the
+// smallest square that fits them, stopping early when fn returns false. The
+// board passed to fn is reused by the search; Clone it to keep a packing.
+func EnumerateSolutions(tetrominos []*Tetromino, fn func(*Board) bool, opts ...Option) error {
+	return EnumerateSolutionsContext(context.Background(), tetrominos, fn, opts...)
+}
+
+// EnumerateSolutionsContext is like EnumerateSolutions but gives up with a
+// *TimeoutError once ctx is done.
+func EnumerateSolutionsContext(ctx context.Context, tetrominos []*Tetromino, fn func(*Board) bool, opts ...Option) error {
+	if len(tetrominos) == 0 {
+		return NewValidationError(ErrInvalidFormat, "no pieces to solve")
+	}
+	o := newOptions(opts)
+
+	for i, t := range tetrominos {
+		t.Letter = rune('A' + i)
+	}
+	sortedTetrominos := sortForSearch(tetrominos)
+	orientations := pieceOrientations(sortedTetrominos, o)
+
+	// Identical pieces share the letter of the first copy in dedup keys.
+	var labels map[rune]rune
+	if o.dedup&DedupIdenticalPieces != 0 {
+		labels = make(map[rune]rune)
+		for _, g := range groupRepetitiveTetrominos(tetrominos) {
+			for _, t := range g.tetrominos {
+				labels[t.Letter] = g.tetrominos[0].Letter
+			}
+		}
+	}
+	seen := make(map[string]bool)
+
+	totalBlocks := totalCells(tetrominos)
+	minSize := int(math.Ceil(math.Sqrt(float64(totalBlocks))))
+	for size := minSize; size <= sizeUpperBound(tetrominos); size++ {
+		board := NewBoard(size)
+		if board == nil {
+			continue
+		}
+
+		s := newSearch(ctx, board, sortedTetrominos)
+		s.orientations = orientations
+		// Symmetry breaking already skips swaps of identical pieces; turn
+		// it off when the caller wants every labelling.
+		if o.dedup&DedupIdenticalPieces == 0 {
+			for i := range s.previous {
+				s.previous[i] = -1
+			}
+		}
+		found := false
+		s.onSolution = func(b *Board) bool {
+			found = true
+			if o.dedup&DedupBoardSymmetry != 0 {
+				key := symmetryKey(b, labels)
+				if seen[key] {
+					return true
+				}
+				seen[key] = true
+			}
+			return fn(b)
+		}
+		s.solve(0)
+		if o.stats != nil {
+			o.stats.add(s.stats)
+		}
+
+		if err := ctx.Err(); err != nil {
+			return &TimeoutError{Err: err}
+		}
+		if found {
+			return nil
+		}
+	}
+	return ErrUnsolvable
+}
+
+// CountSolutions returns the number of packings of tetrominos on the
+// smallest square that fits them.
+func CountSolutions(tetrominos []*Tetromino, opts ...Option) (int, error) {
+	return CountSolutionsContext(context.Background(), tetrominos, opts...)
+}
+
+// CountSolutionsContext is like CountSolutions but gives up with a
+// *TimeoutError once ctx is done.
+func CountSolutionsContext(ctx context.Context, tetrominos []*Tetromino, opts ...Option) (int, error) {
+	count := 0
+	err := EnumerateSolutionsContext(ctx, tetrominos, func(*Board) bool {
+		count++
+		return true
+	}, opts...)
+	return count, err
+}
+
+// symmetryKey renders the board under each of its eight rotations and
+// reflections, with letters mapped through labels, and returns the smallest
+// rendering so that symmetric packings share a key.
+func symmetryKey(b *Board, labels map[rune]rune) string {
+	n := b.Size
+	best := ""
+	for k := 0; k < 8; k++ {
+		var sb strings.Builder
+		for y := 0; y < n; y++ {
+			for x := 0; x < n; x++ {
+				sx, sy := x, y
+				if k&4 != 0 {
+					sx, sy = sy, sx
+				}
+				if k&1 != 0 {
+					sx = n - 1 - sx
+				}
+				if k&2 != 0 {
+					sy = n - 1 - sy
+				}
+				r := b.Grid[sy][sx]
+				if l, ok := labels[r]; ok {
+					r = l
+				}
+				if r == 0 {
+					r = '.'
+				}
+				sb.WriteRune(r)
+			}
+		}
+		if key := sb.String(); k == 0 || key < best {
+			best = key
+		}
+	}
+	return best
+}
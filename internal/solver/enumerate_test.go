@@ -0,0 +1,133 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCountSolutions(t *testing.T) {
+	newBar := func(id int) *Tetromino {
+		bar, err := createTestTetromino([]string{
+			"####",
+			"....",
+			"....",
+			"....",
+		}, id)
+		if err != nil {
+			t.Fatalf("ERROR")
+		}
+		return bar
+	}
+	square, err := createTestTetromino([]string{
+		"##..",
+		"##..",
+		"....",
+		"....",
+	}, 0)
+	if err != nil {
+		t.Fatalf("ERROR")
+	}
+
+	// Two bars fill two of the four rows of a 4x4 board: 12 ordered
+	// pairs, 6 unordered, and flipping the board top to bottom merges
+	// them further.
+	tests := []struct {
+		name       string
+		tetrominos []*Tetromino
+		dedup      Dedup
+		want       int
+	}{
+		{"SingleSquare", []*Tetromino{square}, 0, 1},
+		{"TwoBars", []*Tetromino{newBar(0), newBar(1)}, 0, 12},
+		{"TwoBarsIdentical", []*Tetromino{newBar(0), newBar(1)}, DedupIdenticalPieces, 6},
+		{"TwoBarsSymmetry", []*Tetromino{newBar(0), newBar(1)}, DedupBoardSymmetry, 6},
+		{"TwoBarsBoth", []*Tetromino{newBar(0), newBar(1)}, DedupIdenticalPieces | DedupBoardSymmetry, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CountSolutions(tt.tetrominos, WithDedup(tt.dedup))
+			if err != nil {
+				t.Fatalf("CountSolutions() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CountSolutions() = %d; want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnumerateSolutionsStopsEarly(t *testing.T) {
+	var tetrominos []*Tetromino
+	for i := 0; i < 2; i++ {
+		bar, err := createTestTetromino([]string{
+			"####",
+			"....",
+			"....",
+			"....",
+		}, i)
+		if err != nil {
+			t.Fatalf("ERROR")
+		}
+		tetrominos = append(tetrominos, bar)
+	}
+
+	var boards []string
+	err := EnumerateSolutions(tetrominos, func(b *Board) bool {
+		boards = append(boards, b.String())
+		return len(boards) < 2
+	})
+	if err != nil {
+		t.Fatalf("EnumerateSolutions() error = %v", err)
+	}
+	want := []string{"AAAA\nBBBB\n....\n....", "AAAA\n....\nBBBB\n...."}
+	if len(boards) != len(want) {
+		t.Fatalf("EnumerateSolutions() visited %d boards; want %d", len(boards), len(want))
+	}
+	for i := range want {
+		if boards[i] != want[i] {
+			t.Errorf("EnumerateSolutions() board %d = %q; want %q", i, boards[i], want[i])
+		}
+	}
+}
+
+func TestEnumerateSolutionsErrors(t *testing.T) {
+	if err := EnumerateSolutions(nil, func(*Board) bool { return true }); err == nil {
+		t.Error("EnumerateSolutions() error = nil for no pieces; want error")
+	}
+
+	square, err := createTestTetromino([]string{
+		"##..",
+		"##..",
+		"....",
+		"....",
+	}, 0)
+	if err != nil {
+		t.Fatalf("ERROR")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := CountSolutionsContext(ctx, []*Tetromino{square}); !errors.Is(err, context.Canceled) {
+		t.Errorf("CountSolutionsContext() error = %v; want it to wrap context.Canceled", err)
+	}
+}
+
+func TestSymmetryKey(t *testing.T) {
+	board := NewBoard(3)
+	board.Place(makeTetromino('A', []Point{{0, 0}, {1, 0}}), 0, 0)
+	rotated := NewBoard(3)
+	rotated.Place(makeTetromino('A', []Point{{0, 0}, {0, 1}}), 2, 1)
+	if symmetryKey(board, nil) != symmetryKey(rotated, nil) {
+		t.Errorf("symmetryKey() differs for rotated boards:\n%s\n%s", board, rotated)
+	}
+
+	other := NewBoard(3)
+	other.Place(makeTetromino('B', []Point{{0, 0}, {1, 0}}), 0, 0)
+	if symmetryKey(board, nil) == symmetryKey(other, nil) {
+		t.Error("symmetryKey() matches boards with different letters")
+	}
+	if symmetryKey(board, map[rune]rune{'B': 'A'}) != symmetryKey(other, map[rune]rune{'B': 'A'}) {
+		t.Error("symmetryKey() differs for boards that only swap relabelled letters")
+	}
+}
@@ -1,13 +1,153 @@
 package solver
 
-type validationError struct {
-    message string
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors classify every failure of the package; match them with
+// errors.Is. The concrete types below carry the details.
+var (
+	// ErrInvalidFormat reports input that is not a well-formed puzzle.
+	ErrInvalidFormat = errors.New("invalid puzzle format")
+	// ErrInvalidPiece reports a block that is well formed but not a valid
+	// piece. Such errors also match ErrInvalidFormat.
+	ErrInvalidPiece = errors.New("invalid piece")
+	// ErrUnsolvable reports that no board up to the proven size bound fits
+	// the pieces. Valid tetromino input never produces it.
+	ErrUnsolvable = errors.New("no solution within the board size bound")
+	// ErrInvalidOption reports an option the pieces can never satisfy, such
+	// as a well narrower than the widest piece.
+	ErrInvalidOption = errors.New("invalid option")
+	// ErrTimeout reports that solving stopped before a solution was found
+	// because its context was done.
+	ErrTimeout = errors.New("solving interrupted")
+	// ErrPathRejected reports a file name refused by WithSandbox.
+	ErrPathRejected = errors.New("path rejected")
+	// ErrUnreadable reports a puzzle that could not be read at all.
+	ErrUnreadable = errors.New("puzzle unreadable")
+	// ErrInvalidSolution reports a solution rejected by Verify.
+	ErrInvalidSolution = errors.New("invalid solution")
+	// ErrNoPuzzle reports that Generate found no puzzle meeting its
+	// constraints.
+	ErrNoPuzzle = errors.New("no puzzle meets the constraints")
+)
+
+// ValidationError reports input the solver refuses before parsing it.
+// Kind is one of the sentinel errors and is matched by errors.Is.
+type ValidationError struct {
+	Kind    error
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the sentinel error classifying e.
+func (e *ValidationError) Unwrap() error {
+	return e.Kind
+}
+
+// NewValidationError returns a *ValidationError of the given kind.
+func NewValidationError(kind error, message string) error {
+	return &ValidationError{Kind: kind, Message: message}
+}
+
+// TimeoutError reports that solving stopped because its context was
+// cancelled or its deadline passed before a solution was found.
+type TimeoutError struct {
+	Err error
+}
+
+func (e *TimeoutError) Error() string {
+	return ErrTimeout.Error() + ": " + e.Err.Error()
+}
+
+// Is reports whether target is ErrTimeout.
+func (e *TimeoutError) Is(target error) bool {
+	return target == ErrTimeout
 }
 
-func (e *validationError) Error() string {
-    return e.message
+// Unwrap returns the context error that stopped the search.
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
 }
 
-func newValidationError(message string) error {
-    return &validationError{message: message}
-}
\ No newline at end of file
+// ParseReason classifies why a puzzle was rejected.
+type ParseReason int
+
+const (
+	// ReasonEmpty reports input without any piece.
+	ReasonEmpty ParseReason = iota + 1
+	// ReasonBadCharacter reports a character other than '#', '.' or
+	// surrounding whitespace.
+	ReasonBadCharacter
+	// ReasonRowWidth reports a row that is not as wide as its block.
+	ReasonRowWidth
+	// ReasonIncompleteBlock reports a block with too few rows.
+	ReasonIncompleteBlock
+	// ReasonMissingSeparator reports a block not followed by an empty line.
+	ReasonMissingSeparator
+	// ReasonTooManyCells reports a tetromino with more than four '#'.
+	ReasonTooManyCells
+	// ReasonTooFewCells reports a piece with too few '#'.
+	ReasonTooFewCells
+	// ReasonDisconnected reports a piece whose '#' do not share edges.
+	ReasonDisconnected
+)
+
+var parseReasons = map[ParseReason]string{
+	ReasonEmpty:            "no pieces",
+	ReasonBadCharacter:     "bad character",
+	ReasonRowWidth:         "wrong row width",
+	ReasonIncompleteBlock:  "incomplete block",
+	ReasonMissingSeparator: "missing separator",
+	ReasonTooManyCells:     "too many '#'",
+	ReasonTooFewCells:      "too few '#'",
+	ReasonDisconnected:     "disconnected piece",
+}
+
+func (r ParseReason) String() string {
+	if s, ok := parseReasons[r]; ok {
+		return s
+	}
+	return fmt.Sprintf("ParseReason(%d)", int(r))
+}
+
+// ParseError reports where and why a puzzle was rejected. Line and Column
+// are 1-based; Block is the 0-based index of the piece being read. File is
+// empty when the puzzle did not come from a named file.
+type ParseError struct {
+	File   string
+	Line   int
+	Column int
+	Block  int
+	Reason ParseReason
+}
+
+func (e *ParseError) Error() string {
+	pos := fmt.Sprintf("%d:%d", e.Line, e.Column)
+	if e.File != "" {
+		pos = e.File + ":" + pos
+	}
+	return fmt.Sprintf("%s: block %d: %s", pos, e.Block+1, e.Reason)
+}
+
+// Is reports whether target is ErrInvalidFormat, or ErrInvalidPiece when e
+// rejects the shape of a block rather than its layout.
+func (e *ParseError) Is(target error) bool {
+	switch target {
+	case ErrInvalidFormat:
+		return true
+	case ErrInvalidPiece:
+		return e.Reason == ReasonTooManyCells || e.Reason == ReasonTooFewCells || e.Reason == ReasonDisconnected
+	}
+	return false
+}
+
+// newParseError returns a *ParseError for the 0-based row and column of a
+// block.
+func newParseError(reason ParseReason, block, row, col int) *ParseError {
+	return &ParseError{Line: row + 1, Column: col + 1, Block: block, Reason: reason}
+}
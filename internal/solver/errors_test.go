@@ -1,24 +1,85 @@
 package solver
 
 import (
+	"context"
 	"errors"
 	"testing"
 )
 
 func TestValidationErrorImplementsError(t *testing.T) {
-	err := newValidationError("invalid input")
+	err := NewValidationError(ErrPathRejected, "invalid input")
 
-	var e *validationError
+	var e *ValidationError
 	if !errors.As(err, &e) {
-		t.Errorf("expected error to be of type *validationError, got %T", err)
+		t.Errorf("expected error to be of type *ValidationError, got %T", err)
+	}
+	if !errors.Is(err, ErrPathRejected) {
+		t.Errorf("expected error to match ErrPathRejected, got %v", err)
+	}
+	if errors.Is(err, ErrUnreadable) {
+		t.Errorf("expected error not to match ErrUnreadable")
 	}
 }
 
 func TestValidationErrorMessage(t *testing.T) {
 	msg := "this is a validation error"
-	err := newValidationError(msg)
+	err := NewValidationError(ErrUnreadable, msg)
 
 	if err.Error() != msg {
 		t.Errorf("expected error message %q, got %q", msg, err.Error())
 	}
 }
+
+func TestTimeoutErrorUnwrap(t *testing.T) {
+	err := error(&TimeoutError{Err: context.DeadlineExceeded})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected error to wrap context.DeadlineExceeded, got %v", err)
+	}
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("expected error to match ErrTimeout, got %v", err)
+	}
+	if want := "solving interrupted: context deadline exceeded"; err.Error() != want {
+		t.Errorf("expected error message %q, got %q", want, err.Error())
+	}
+}
+
+func TestParseErrorMessage(t *testing.T) {
+	err := &ParseError{Line: 6, Column: 3, Block: 1, Reason: ReasonDisconnected}
+	if want := "6:3: block 2: disconnected piece"; err.Error() != want {
+		t.Errorf("expected error message %q, got %q", want, err.Error())
+	}
+
+	err.File = "puzzle.txt"
+	if want := "puzzle.txt:6:3: block 2: disconnected piece"; err.Error() != want {
+		t.Errorf("expected error message %q, got %q", want, err.Error())
+	}
+	if got := ParseReason(99).String(); got != "ParseReason(99)" {
+		t.Errorf("expected unknown reason to print its value, got %q", got)
+	}
+}
+
+func TestParseErrorIs(t *testing.T) {
+	tests := []struct {
+		reason    ParseReason
+		wantPiece bool
+	}{
+		{ReasonBadCharacter, false},
+		{ReasonMissingSeparator, false},
+		{ReasonTooManyCells, true},
+		{ReasonDisconnected, true},
+	}
+
+	for _, tt := range tests {
+		err := error(&ParseError{Reason: tt.reason})
+		if !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("%v: expected error to match ErrInvalidFormat", tt.reason)
+		}
+		if got := errors.Is(err, ErrInvalidPiece); got != tt.wantPiece {
+			t.Errorf("%v: errors.Is(err, ErrInvalidPiece) = %v, want %v", tt.reason, got, tt.wantPiece)
+		}
+		if errors.Is(err, ErrUnsolvable) {
+			t.Errorf("%v: expected error not to match ErrUnsolvable", tt.reason)
+		}
+	}
+}
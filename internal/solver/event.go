@@ -0,0 +1,28 @@
+package solver
+
+// EventKind says what a search Event did.
+type EventKind int
+
+const (
+	// EventPlace reports a piece put on the board.
+	EventPlace EventKind = iota
+	// EventRemove reports a piece taken back off the board.
+	EventRemove
+)
+
+func (k EventKind) String() string {
+	if k == EventRemove {
+		return "remove"
+	}
+	return "place"
+}
+
+// Event reports one step of the search to a WithTrace hook. Board is the
+// board being searched, already updated; it keeps changing after the hook
+// returns, so Clone it to keep a snapshot.
+type Event struct {
+	Kind  EventKind
+	Piece *Tetromino
+	X, Y  int
+	Board *Board
+}
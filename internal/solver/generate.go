@@ -0,0 +1,161 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// ShapeNames lists the seven tetromino shapes Generate draws from, in the
+// order their weights are applied.
+const ShapeNames = "IOTSZJL"
+
+// shapeRows draws each shape in one orientation; Generate turns it.
+var shapeRows = map[rune][]string{
+	'I': {"####"},
+	'O': {"##", "##"},
+	'T': {"###", ".#."},
+	'S': {".##", "##."},
+	'Z': {"##.", ".##"},
+	'J': {"#..", "###"},
+	'L': {"..#", "###"},
+}
+
+// maxGeneratedPieces keeps generated puzzles within the letters A to Z.
+const maxGeneratedPieces = 26
+
+// GenerateOptions configures Generate.
+type GenerateOptions struct {
+	Pieces int   // number of pieces, from 1 to 26
+	Seed   int64 // the same seed and options always give the same puzzle
+
+	// Weights sets how often each shape of ShapeNames is drawn, relative
+	// to the others. Shapes left out are never drawn; nil draws all seven
+	// equally.
+	Weights map[rune]float64
+
+	// Empty, when set, requires the optimal solution of the puzzle to leave
+	// exactly this many empty cells. Generate solves each candidate and
+	// draws again until one fits, up to MaxAttempts times.
+	Empty       *int
+	MaxAttempts int // 1000 when zero
+}
+
+// Generate returns a random puzzle in the input file format.
+func Generate(opts GenerateOptions) (string, error) {
+	return GenerateContext(context.Background(), opts)
+}
+
+// GenerateContext is like Generate but stops solving candidates once ctx is
+// done.
+func GenerateContext(ctx context.Context, opts GenerateOptions) (string, error) {
+	if opts.Pieces < 1 || opts.Pieces > maxGeneratedPieces {
+		return "", fmt.Errorf("generate: pieces must be between 1 and %d, got %d", maxGeneratedPieces, opts.Pieces)
+	}
+	shapes, weights, err := shapeWeights(opts.Weights)
+	if err != nil {
+		return "", err
+	}
+	attempts := opts.MaxAttempts
+	if attempts <= 0 {
+		attempts = 1000
+	}
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	for i := 0; i < attempts; i++ {
+		puzzle := drawPuzzle(rng, opts.Pieces, shapes, weights)
+		if opts.Empty == nil {
+			return puzzle, nil
+		}
+
+		tetrominos, err := parseTetrominos(puzzle, newOptions(nil))
+		if err != nil {
+			return "", err
+		}
+		board, err := SolveBoardContext(ctx, tetrominos, WithMinimalSize())
+		if err != nil {
+			return "", err
+		}
+		if board.Empty() == *opts.Empty {
+			return puzzle, nil
+		}
+	}
+	return "", fmt.Errorf("%w: no puzzle with %d empty cells in %d attempts", ErrNoPuzzle, *opts.Empty, attempts)
+}
+
+// shapeWeights returns the orientations of every shape that may be drawn
+// together with the cumulative weights to pick them by.
+func shapeWeights(weights map[rune]float64) ([][]*Tetromino, []float64, error) {
+	for name, w := range weights {
+		if !strings.ContainsRune(ShapeNames, name) {
+			return nil, nil, fmt.Errorf("generate: unknown shape %q", name)
+		}
+		if w < 0 {
+			return nil, nil, fmt.Errorf("generate: shape %c has negative weight %v", name, w)
+		}
+	}
+
+	var (
+		shapes [][]*Tetromino
+		cumul  []float64
+		total  float64
+	)
+	for _, name := range ShapeNames {
+		w := 1.0
+		if weights != nil {
+			w = weights[name]
+		}
+		if w == 0 {
+			continue
+		}
+		var points []Point
+		for y, row := range shapeRows[name] {
+			for x, c := range row {
+				if c == '#' {
+					points = append(points, Point{X: x, Y: y})
+				}
+			}
+		}
+		total += w
+		shapes = append(shapes, newOrientation(points, 0).Orientations(false))
+		cumul = append(cumul, total)
+	}
+	if len(shapes) == 0 {
+		return nil, nil, fmt.Errorf("generate: every shape has zero weight")
+	}
+	return shapes, cumul, nil
+}
+
+// drawPuzzle draws n pieces, each a random orientation of a shape picked
+// by weight and put at a random offset in its 4x4 block.
+func drawPuzzle(rng *rand.Rand, n int, shapes [][]*Tetromino, cumul []float64) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		r := rng.Float64() * cumul[len(cumul)-1]
+		s := 0
+		for cumul[s] <= r {
+			s++
+		}
+		t := shapes[s][rng.Intn(len(shapes[s]))]
+		dx, dy := rng.Intn(5-t.Width), rng.Intn(5-t.Height)
+
+		var block [4][4]byte
+		for y := range block {
+			for x := range block[y] {
+				block[y][x] = '.'
+			}
+		}
+		for _, p := range t.Points {
+			block[p.Y+dy][p.X+dx] = '#'
+		}
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		for _, row := range block {
+			b.Write(row[:])
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
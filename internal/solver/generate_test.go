@@ -0,0 +1,91 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	puzzle, err := Generate(GenerateOptions{Pieces: 8, Seed: 42})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	tetrominos, err := parseTetrominos(puzzle, newOptions(nil))
+	if err != nil {
+		t.Fatalf("Generate() wrote an invalid puzzle: %v\n%s", err, puzzle)
+	}
+	if len(tetrominos) != 8 {
+		t.Errorf("Generate() wrote %d pieces; want 8", len(tetrominos))
+	}
+
+	again, err := Generate(GenerateOptions{Pieces: 8, Seed: 42})
+	if err != nil || again != puzzle {
+		t.Errorf("Generate() with the same seed = %q, %v; want %q", again, err, puzzle)
+	}
+	other, err := Generate(GenerateOptions{Pieces: 8, Seed: 43})
+	if err != nil || other == puzzle {
+		t.Errorf("Generate() with another seed = %q, %v; want a different puzzle", other, err)
+	}
+}
+
+func TestGenerateWeights(t *testing.T) {
+	puzzle, err := Generate(GenerateOptions{Pieces: 5, Seed: 1, Weights: map[rune]float64{'O': 1}})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	tetrominos, err := parseTetrominos(puzzle, newOptions(nil))
+	if err != nil {
+		t.Fatalf("Generate() wrote an invalid puzzle: %v", err)
+	}
+	for i, tt := range tetrominos {
+		if tt.Width != 2 || tt.Height != 2 {
+			t.Errorf("piece %d is %dx%d; want only O pieces", i, tt.Width, tt.Height)
+		}
+	}
+
+	for _, weights := range []map[rune]float64{
+		{'X': 1},
+		{'I': -1},
+		{'I': 0},
+	} {
+		if _, err := Generate(GenerateOptions{Pieces: 1, Weights: weights}); err == nil {
+			t.Errorf("Generate(Weights: %v) error = nil; want error", weights)
+		}
+	}
+	for _, n := range []int{0, 27} {
+		if _, err := Generate(GenerateOptions{Pieces: n}); err == nil {
+			t.Errorf("Generate(Pieces: %d) error = nil; want error", n)
+		}
+	}
+}
+
+func TestGenerateEmpty(t *testing.T) {
+	for _, empty := range []int{0, 9} {
+		puzzle, err := Generate(GenerateOptions{Pieces: 4, Seed: 7, Empty: &empty})
+		if err != nil {
+			t.Fatalf("Generate(Empty: %d) error = %v", empty, err)
+		}
+		solution, err := validateAndSolve(context.Background(), puzzle)
+		if err != nil {
+			t.Fatalf("validateAndSolve() error = %v", err)
+		}
+		if got := strings.Count(solution, "."); got != empty {
+			t.Errorf("Generate(Empty: %d) solution %q has %d empty cells", empty, solution, got)
+		}
+	}
+
+	// Seed 12615 draws eight Ts turned the same way, which the grid layout
+	// packs on 8x8 but which fit on 7x7.
+	optimal := 7*7 - 8*4
+	if _, err := Generate(GenerateOptions{Pieces: 8, Seed: 12615, Weights: map[rune]float64{'T': 1}, Empty: &optimal, MaxAttempts: 1}); err != nil {
+		t.Errorf("Generate(Empty: %d) of repeated pieces error = %v", optimal, err)
+	}
+
+	impossible := 1
+	_, err := Generate(GenerateOptions{Pieces: 1, Weights: map[rune]float64{'O': 1}, Empty: &impossible, MaxAttempts: 5})
+	if !errors.Is(err, ErrNoPuzzle) {
+		t.Errorf("Generate() error = %v; want ErrNoPuzzle", err)
+	}
+}
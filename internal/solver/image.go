@@ -0,0 +1,208 @@
+package solver
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io"
+)
+
+// ImageOptions configures RenderPNG.
+type ImageOptions struct {
+	CellSize int // side of one cell in pixels; 40 when zero
+}
+
+// GIFOptions configures a GIFRecorder.
+type GIFOptions struct {
+	CellSize  int // side of one cell in pixels; 20 when zero
+	MaxFrames int // search steps kept in the animation; 500 when zero
+	Delay     int // time between frames in hundredths of a second; 5 when zero
+}
+
+// Palette indexes shared by every raster image. Pieces take the rest of
+// the palette in letter order.
+const (
+	paletteBackground = iota
+	paletteGrid
+	paletteOutline
+	palettePieces
+)
+
+// imagePad leaves room around the board for the thick outline.
+const imagePad = 2
+
+// piecePalette returns the background, grid and outline colours followed
+// by PieceColor for as many pieces as a palette holds.
+func piecePalette() color.Palette {
+	p := color.Palette{color.White, color.RGBA{R: 0xcc, G: 0xcc, B: 0xcc, A: 0xff}, color.Black}
+	for i := 0; len(p) < 256; i++ {
+		p = append(p, PieceColor(i))
+	}
+	return p
+}
+
+// colorIndex maps a Grid rune to its palette index.
+func colorIndex(r rune) uint8 {
+	if r == 0 {
+		return paletteBackground
+	}
+	return uint8(palettePieces + int(r-'A')%(256-palettePieces))
+}
+
+// RenderPNG draws the board as a PNG image, with the same colours and
+// outlines as RenderSVG.
+func RenderPNG(w io.Writer, b *Board, opts ImageOptions) error {
+	cell := opts.CellSize
+	if cell <= 0 {
+		cell = defaultCellSize
+	}
+	img := newBoardImage(b.Width, b.Height, cell)
+	drawGrid(img, b.Grid, cell)
+	return png.Encode(w, img)
+}
+
+// newBoardImage returns a blank image for a width x height board.
+func newBoardImage(width, height, cell int) *image.Paletted {
+	rect := image.Rect(0, 0, width*cell+2*imagePad, height*cell+2*imagePad)
+	return image.NewPaletted(rect, piecePalette())
+}
+
+// drawGrid paints the cells of grid, thin lines around empty cells and
+// thick outlines wherever two pieces, or a piece and an empty cell, meet.
+func drawGrid(img *image.Paletted, grid [][]rune, cell int) {
+	at := func(x, y int) rune {
+		if y < 0 || y >= len(grid) || x < 0 || x >= len(grid[y]) {
+			return 0
+		}
+		return grid[y][x]
+	}
+	for y, row := range grid {
+		for x, r := range row {
+			fillRect(img, x*cell, y*cell, cell, cell, colorIndex(r))
+		}
+	}
+
+	height := len(grid)
+	if height == 0 {
+		return
+	}
+	width := len(grid[0])
+	for _, thick := range []bool{false, true} {
+		for y := 0; y <= height; y++ {
+			for x := 0; x <= width; x++ {
+				if x < width {
+					if a, b := at(x, y-1), at(x, y); (a != b) == thick && (thick || a == 0) {
+						drawEdge(img, x*cell, y*cell, cell, 1, thick)
+					}
+				}
+				if y < height {
+					if a, b := at(x-1, y), at(x, y); (a != b) == thick && (thick || a == 0) {
+						drawEdge(img, x*cell, y*cell, 1, cell, thick)
+					}
+				}
+			}
+		}
+	}
+}
+
+// drawEdge draws the cell edge from (x, y) spanning w x h board pixels,
+// where one of w and h is 1.
+func drawEdge(img *image.Paletted, x, y, w, h int, thick bool) {
+	if !thick {
+		fillRect(img, x, y, w, h, paletteGrid)
+		return
+	}
+	fillRect(img, x-1, y-1, w+2, h+2, paletteOutline)
+}
+
+// fillRect paints a rectangle given in board pixels, which are offset by
+// imagePad in the image.
+func fillRect(img *image.Paletted, x, y, w, h int, c uint8) {
+	r := image.Rect(x, y, x+w, y+h).Add(image.Pt(imagePad, imagePad)).Intersect(img.Rect)
+	for py := r.Min.Y; py < r.Max.Y; py++ {
+		for px := r.Min.X; px < r.Max.X; px++ {
+			img.SetColorIndex(px, py, c)
+		}
+	}
+}
+
+// GIFRecorder turns the events of a traced search into an animated GIF;
+// pass its Record method to WithTrace. When the search takes more steps
+// than MaxFrames it keeps every second step, then every fourth and so on,
+// so the animation always spans the whole search.
+type GIFRecorder struct {
+	opts   GIFOptions
+	frames [][][]rune
+	stride int
+	seen   int
+}
+
+// NewGIFRecorder returns a recorder with no frames.
+func NewGIFRecorder(opts GIFOptions) *GIFRecorder {
+	if opts.CellSize <= 0 {
+		opts.CellSize = 20
+	}
+	if opts.MaxFrames <= 0 {
+		opts.MaxFrames = 500
+	}
+	if opts.Delay <= 0 {
+		opts.Delay = 5
+	}
+	return &GIFRecorder{opts: opts, stride: 1}
+}
+
+// Record keeps a snapshot of the board after e.
+func (r *GIFRecorder) Record(e Event) {
+	r.seen++
+	if r.seen%r.stride != 0 {
+		return
+	}
+	r.frames = append(r.frames, snapshot(e.Board))
+	if len(r.frames) >= r.opts.MaxFrames {
+		kept := r.frames[:0]
+		for i := 1; i < len(r.frames); i += 2 {
+			kept = append(kept, r.frames[i])
+		}
+		r.frames = kept
+		r.stride *= 2
+	}
+}
+
+// Encode writes the recorded steps followed by final, which is held on
+// screen for three seconds.
+func (r *GIFRecorder) Encode(w io.Writer, final *Board) error {
+	frames := r.frames
+	if final != nil {
+		frames = append(frames[:len(frames):len(frames)], snapshot(final))
+	}
+	width, height := 0, 0
+	for _, f := range frames {
+		height = max(height, len(f))
+		if len(f) > 0 {
+			width = max(width, len(f[0]))
+		}
+	}
+
+	anim := &gif.GIF{}
+	for i, f := range frames {
+		img := newBoardImage(width, height, r.opts.CellSize)
+		drawGrid(img, f, r.opts.CellSize)
+		delay := r.opts.Delay
+		if final != nil && i == len(frames)-1 {
+			delay = 300
+		}
+		anim.Image = append(anim.Image, img)
+		anim.Delay = append(anim.Delay, delay)
+	}
+	return gif.EncodeAll(w, anim)
+}
+
+// snapshot copies the board's grid.
+func snapshot(b *Board) [][]rune {
+	grid := make([][]rune, len(b.Grid))
+	for y, row := range b.Grid {
+		grid[y] = append([]rune(nil), row...)
+	}
+	return grid
+}
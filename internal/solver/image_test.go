@@ -0,0 +1,92 @@
+package solver
+
+import (
+	"bytes"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+)
+
+func TestRenderPNG(t *testing.T) {
+	board := NewRectBoard(3, 2)
+	board.Place(makeTetromino('A', []Point{{0, 0}, {1, 0}, {1, 1}}), 0, 0)
+
+	var buf bytes.Buffer
+	if err := RenderPNG(&buf, board, ImageOptions{CellSize: 10}); err != nil {
+		t.Fatalf("RenderPNG() error = %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("RenderPNG() wrote an invalid PNG: %v", err)
+	}
+	if got := img.Bounds().Size(); got.X != 34 || got.Y != 24 {
+		t.Fatalf("RenderPNG() size = %v; want 34x24", got)
+	}
+
+	rgba := func(x, y int) color.RGBA {
+		return color.RGBAModel.Convert(img.At(x+imagePad, y+imagePad)).(color.RGBA)
+	}
+	tests := []struct {
+		name string
+		x, y int
+		want color.RGBA
+	}{
+		{"PieceCell", 5, 5, PieceColor(0)},
+		{"EmptyCell", 25, 15, color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}},
+		{"PieceOutline", 10, 15, color.RGBA{A: 0xff}},
+		{"InsidePiece", 10, 5, PieceColor(0)},
+		{"GridLine", 25, 10, color.RGBA{R: 0xcc, G: 0xcc, B: 0xcc, A: 0xff}},
+	}
+	for _, tt := range tests {
+		if got := rgba(tt.x, tt.y); got != tt.want {
+			t.Errorf("RenderPNG() %s at (%d, %d) = %v; want %v", tt.name, tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestGIFRecorder(t *testing.T) {
+	tetrominos := make([]*Tetromino, 4)
+	for i, rows := range [][]string{
+		{"#...", "#...", "#...", "#..."},
+		{"....", "####", "....", "...."},
+		{".#..", "##..", "#...", "...."},
+		{"....", "....", "##..", ".##."},
+	} {
+		var err error
+		if tetrominos[i], err = createTestTetromino(rows, i); err != nil {
+			t.Fatalf("createTestTetromino() error = %v", err)
+		}
+	}
+
+	rec := NewGIFRecorder(GIFOptions{CellSize: 4, MaxFrames: 8})
+	board, err := SolveBoard(tetrominos, WithTrace(rec.Record))
+	if err != nil {
+		t.Fatalf("SolveBoard() error = %v", err)
+	}
+	if rec.seen <= 8 {
+		t.Fatalf("recorded %d events; want more than MaxFrames to exercise thinning", rec.seen)
+	}
+	if len(rec.frames) >= 8 || rec.stride < 2 {
+		t.Errorf("kept %d frames with stride %d; want fewer than 8 with stride >= 2", len(rec.frames), rec.stride)
+	}
+
+	var buf bytes.Buffer
+	if err := rec.Encode(&buf, board); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	anim, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatalf("Encode() wrote an invalid GIF: %v", err)
+	}
+	if len(anim.Image) != len(rec.frames)+1 {
+		t.Errorf("Encode() wrote %d frames; want %d", len(anim.Image), len(rec.frames)+1)
+	}
+	if last := anim.Delay[len(anim.Delay)-1]; last != 300 {
+		t.Errorf("Encode() final frame delay = %d; want 300", last)
+	}
+	want := board.Width*4 + 2*imagePad
+	if got := anim.Image[0].Bounds().Dx(); got != want {
+		t.Errorf("Encode() frame width = %d; want %d", got, want)
+	}
+}
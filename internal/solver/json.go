@@ -0,0 +1,102 @@
+package solver
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PiecePlacement describes where one piece sits on a solved board.
+type PiecePlacement struct {
+	Letter string  `json:"letter"`
+	Index  int     `json:"index"` // position of the piece in the input
+	X      int     `json:"x"`     // left edge of the piece's bounding box
+	Y      int     `json:"y"`     // top edge of the piece's bounding box
+	Cells  []Point `json:"cells"` // occupied cells in board coordinates
+}
+
+// Placements lists the pieces on the board in input order. They are read
+// back from the letters in Grid, so rotated pieces report the cells they
+// actually cover.
+func (b *Board) Placements() []PiecePlacement {
+	byLetter := make(map[rune]*PiecePlacement)
+	for y, row := range b.Grid {
+		for x, r := range row {
+			if r == 0 {
+				continue
+			}
+			p, ok := byLetter[r]
+			if !ok {
+				p = &PiecePlacement{Letter: string(r), Index: int(r - 'A'), X: x, Y: y}
+				byLetter[r] = p
+			}
+			p.X = min(p.X, x)
+			p.Cells = append(p.Cells, Point{X: x, Y: y})
+		}
+	}
+
+	placements := make([]PiecePlacement, 0, len(byLetter))
+	for _, p := range byLetter {
+		placements = append(placements, *p)
+	}
+	sort.Slice(placements, func(i, j int) bool {
+		return placements[i].Index < placements[j].Index
+	})
+	return placements
+}
+
+// Empty returns the number of cells no piece covers.
+func (b *Board) Empty() int {
+	empty := 0
+	for _, row := range b.Grid {
+		for _, r := range row {
+			if r == 0 {
+				empty++
+			}
+		}
+	}
+	return empty
+}
+
+// boardJSON is the JSON form of a Board.
+type boardJSON struct {
+	Size   int              `json:"size,omitempty"`
+	Width  int              `json:"width"`
+	Height int              `json:"height"`
+	Rows   []string         `json:"rows"`
+	Pieces []PiecePlacement `json:"pieces"`
+	Empty  int              `json:"empty"`
+}
+
+func (b *Board) toJSON() boardJSON {
+	return boardJSON{
+		Size:   b.Size,
+		Width:  b.Width,
+		Height: b.Height,
+		Rows:   strings.Split(b.String(), "\n"),
+		Pieces: b.Placements(),
+		Empty:  b.Empty(),
+	}
+}
+
+// MarshalJSON encodes the board's dimensions, rows, piece placements and
+// empty cell count.
+func (b *Board) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.toJSON())
+}
+
+// Solution is a solved board together with the time it took to solve.
+type Solution struct {
+	Board   *Board
+	Elapsed time.Duration
+}
+
+// MarshalJSON encodes the board as Board.MarshalJSON does, plus the solve
+// time in milliseconds.
+func (s Solution) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		boardJSON
+		ElapsedMs float64 `json:"elapsedMs"`
+	}{s.Board.toJSON(), float64(s.Elapsed) / float64(time.Millisecond)})
+}
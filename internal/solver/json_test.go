@@ -0,0 +1,63 @@
+package solver
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBoardPlacements(t *testing.T) {
+	square := makeTetromino('A', []Point{{0, 0}, {1, 0}, {0, 1}, {1, 1}})
+	tee := makeTetromino('B', []Point{{1, 0}, {0, 1}, {1, 1}, {2, 1}})
+	board := NewBoard(4)
+	board.Place(tee, 1, 2)
+	board.Place(square, 0, 0)
+
+	want := []PiecePlacement{
+		{Letter: "A", Index: 0, X: 0, Y: 0, Cells: []Point{{0, 0}, {1, 0}, {0, 1}, {1, 1}}},
+		{Letter: "B", Index: 1, X: 1, Y: 2, Cells: []Point{{2, 2}, {1, 3}, {2, 3}, {3, 3}}},
+	}
+	if got := board.Placements(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Placements() = %+v; want %+v", got, want)
+	}
+	if got := board.Empty(); got != 8 {
+		t.Errorf("Empty() = %d; want 8", got)
+	}
+}
+
+func TestBoardMarshalJSON(t *testing.T) {
+	board := NewRectBoard(3, 2)
+	board.Place(makeTetromino('A', []Point{{0, 0}, {1, 0}, {1, 1}}), 1, 0)
+
+	got, err := json.Marshal(board)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	want := `{"width":3,"height":2,"rows":[".AA","..A"],"pieces":[{"letter":"A","index":0,"x":1,"y":0,"cells":[{"x":1,"y":0},{"x":2,"y":0},{"x":2,"y":1}]}],"empty":3}`
+	if string(got) != want {
+		t.Errorf("json.Marshal() = %s; want %s", got, want)
+	}
+}
+
+func TestSolutionMarshalJSON(t *testing.T) {
+	board := NewBoard(2)
+	board.Place(makeTetromino('A', []Point{{0, 0}, {1, 0}, {0, 1}, {1, 1}}), 0, 0)
+
+	got, err := json.Marshal(Solution{Board: board, Elapsed: 1500 * time.Microsecond})
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var decoded struct {
+		Size      int      `json:"size"`
+		Rows      []string `json:"rows"`
+		Empty     int      `json:"empty"`
+		ElapsedMs float64  `json:"elapsedMs"`
+	}
+	if err := json.Unmarshal(got, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if decoded.Size != 2 || !reflect.DeepEqual(decoded.Rows, []string{"AA", "AA"}) || decoded.Empty != 0 || decoded.ElapsedMs != 1.5 {
+		t.Errorf("json.Marshal() = %s; want a 2x2 board solved in 1.5ms", got)
+	}
+}
@@ -0,0 +1,178 @@
+package solver
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Algorithm selects the search engine used by the general solver.
+type Algorithm int
+
+const (
+	// Backtracking tries every position for every piece in turn.
+	Backtracking Algorithm = iota
+	// DancingLinks solves the placement as an exact-cover problem with
+	// Algorithm X, pruning dead regions and repeated pieces as Backtracking
+	// does. The matrix costs some speed, so Backtracking stays the default.
+	DancingLinks
+)
+
+// Option configures how a puzzle is read and solved.
+type Option func(*options)
+
+// Dedup selects which packings EnumerateSolutions treats as the same.
+type Dedup int
+
+const (
+	// DedupIdenticalPieces merges packings that differ only by swapping
+	// identical pieces.
+	DedupIdenticalPieces Dedup = 1 << iota
+	// DedupBoardSymmetry merges packings that differ only by rotating or
+	// reflecting the whole board.
+	DedupBoardSymmetry
+)
+
+type options struct {
+	algorithm   Algorithm
+	workers     int
+	dedup       Dedup
+	width       int
+	rectangle   bool
+	rotate      bool
+	mirror      bool
+	polyominoes bool
+	sandbox     string
+	trace       func(Event)
+	stats       *Stats
+	logger      *slog.Logger
+	progress    func(Progress)
+	minimal     bool
+}
+
+// newOptions applies opts over the default configuration.
+func newOptions(opts []Option) *options {
+	o := &options{algorithm: Backtracking, workers: 1}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithAlgorithm selects the search engine used by the general solver.
+func WithAlgorithm(a Algorithm) Option {
+	return func(o *options) {
+		o.algorithm = a
+	}
+}
+
+// WithWorkers spreads the general search over up to n goroutines. Values
+// below 2 keep the search sequential; the result is the same either way.
+func WithWorkers(n int) Option {
+	return func(o *options) {
+		o.workers = n
+	}
+}
+
+// WithDedup makes EnumerateSolutions and CountSolutions report only one
+// packing of each class selected by d.
+func WithDedup(d Dedup) Option {
+	return func(o *options) {
+		o.dedup = d
+	}
+}
+
+// WithWidth packs the pieces into a board of the given width, like a Tetris
+// well, and looks for the lowest height instead of the smallest square.
+func WithWidth(width int) Option {
+	return func(o *options) {
+		o.width = width
+	}
+}
+
+// WithRectangle looks for the rectangle of smallest area instead of the
+// smallest square. Among boards of equal area the squarest one wins.
+func WithRectangle() Option {
+	return func(o *options) {
+		o.rectangle = true
+	}
+}
+
+// WithRotations lets the solver turn pieces to any of their rotations, and
+// with mirror also flip them over. Each piece keeps its single letter.
+func WithRotations(mirror bool) Option {
+	return func(o *options) {
+		o.rotate = true
+		o.mirror = mirror
+	}
+}
+
+// WithPolyominoes accepts pieces of any number of connected blocks. Each
+// piece is drawn in a KxK block, where K is the width of the first line;
+// without this option every block must be a 4x4 tetromino.
+func WithPolyominoes() Option {
+	return func(o *options) {
+		o.polyominoes = true
+	}
+}
+
+// WithSandbox confines ReadTetrominos to .txt files inside dir. Relative
+// names are resolved against dir and paths escaping it are rejected, as the
+// audit environment requires.
+func WithSandbox(dir string) Option {
+	return func(o *options) {
+		o.sandbox = dir
+	}
+}
+
+// WithTrace calls fn for every piece the solver places or removes, so the
+// search can be watched or recorded. Tracing keeps the search sequential
+// and uses backtracking whatever WithAlgorithm and WithWorkers say.
+func WithTrace(fn func(Event)) Option {
+	return func(o *options) {
+		o.trace = fn
+	}
+}
+
+// WithMinimalSize makes Verify also require the smallest board the solver
+// can find for the pieces. Solving with it skips the grid layout of
+// repeated pieces, which is quick but not always the smallest board.
+func WithMinimalSize() Option {
+	return func(o *options) {
+		o.minimal = true
+	}
+}
+
+// WithStats adds the counts and timings of the search to st. The counts of
+// parallel workers are summed, so they depend on WithWorkers; use a single
+// worker to compare puzzles.
+func WithStats(st *Stats) Option {
+	return func(o *options) {
+		o.stats = st
+	}
+}
+
+// WithLogger reports the progress of the solver to l: which solver ran and
+// how long it took at Info level, and every board size tried at Debug
+// level. The handler's level sets how much is logged.
+func WithLogger(l *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// WithProgress calls fn as the general solver starts each board size and,
+// with backtracking, every few thousand nodes in between, so long solves
+// can be watched. Calls never overlap, even with several workers, but fn
+// should return quickly as the search waits for it.
+func WithProgress(fn func(Progress)) Option {
+	return func(o *options) {
+		o.progress = fn
+	}
+}
+
+// log writes a record to the logger set by WithLogger, if any.
+func (o *options) log(ctx context.Context, level slog.Level, msg string, args ...any) {
+	if o.logger != nil {
+		o.logger.Log(ctx, level, msg, args...)
+	}
+}
@@ -0,0 +1,79 @@
+package solver
+
+import (
+	"context"
+	"sync"
+)
+
+// branchFunc finishes a search on board once the first piece sits at (x, y)
+// in orientation t.
+type branchFunc func(ctx context.Context, board *Board, t *Tetromino, x, y int) bool
+
+// solveParallel hands each placement of the first piece to a pool of
+// workers, each searching its own copy of the board. When a branch succeeds
+// every later branch is cancelled, but earlier ones run to completion so the
+// earliest successful branch wins, exactly as in the sequential search. On
+// success the winning placement is copied into board.
+func solveParallel(ctx context.Context, board *Board, orientations [][]*Tetromino, workers int, branch branchFunc) bool {
+	if len(orientations) == 0 {
+		return true
+	}
+	// Build the masks up front so workers never write to shared pieces.
+	for _, piece := range orientations {
+		for _, t := range piece {
+			t.mask()
+		}
+	}
+
+	positions := placementsOf(board, orientations[0])
+
+	var (
+		mu      sync.Mutex
+		next    int
+		best    = len(positions)
+		winner  *Board
+		cancels = make([]context.CancelFunc, len(positions))
+		wg      sync.WaitGroup
+	)
+	for w := 0; w < min(workers, len(positions)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				mu.Lock()
+				i := next
+				next++
+				if i >= best {
+					mu.Unlock()
+					return
+				}
+				branchCtx, cancel := context.WithCancel(ctx)
+				cancels[i] = cancel
+				mu.Unlock()
+
+				b := board.Clone()
+				p := positions[i]
+				ok := branch(branchCtx, b, p.t, p.x, p.y)
+				cancel()
+
+				mu.Lock()
+				if ok && i < best {
+					best, winner = i, b
+					for _, c := range cancels[i+1:] {
+						if c != nil {
+							c()
+						}
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if winner == nil {
+		return false
+	}
+	*board = *winner
+	return true
+}
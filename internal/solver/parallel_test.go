@@ -0,0 +1,73 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestSolveParallelMatchesSequential(t *testing.T) {
+	shapes := [][]string{
+		{"...#", "...#", "...#", "...#"},
+		{"....", "....", "....", "####"},
+		{".###", "...#", "....", "...."},
+		{"....", "..##", ".##.", "...."},
+		{"....", ".##.", ".##.", "...."},
+		{"....", "....", "##..", ".##."},
+		{"##..", ".#..", ".#..", "...."},
+		{"....", "###.", ".#..", "...."},
+		{"....", "###.", ".#..", "...."},
+	}
+	var tetrominos []*Tetromino
+	for i, shape := range shapes {
+		tetromino, err := createTestTetromino(shape, i)
+		if err != nil {
+			t.Fatalf("ERROR")
+		}
+		tetrominos = append(tetrominos, tetromino)
+	}
+
+	for _, algorithm := range []Algorithm{Backtracking, DancingLinks} {
+		want, err := generalSquareSolver(context.Background(), tetrominos, WithAlgorithm(algorithm))
+		if err != nil {
+			t.Fatalf("generalSquareSolver() error = %v", err)
+		}
+		for _, workers := range []int{2, 3, 8, 64} {
+			got, err := generalSquareSolver(context.Background(), tetrominos, WithAlgorithm(algorithm), WithWorkers(workers))
+			if err != nil {
+				t.Fatalf("generalSquareSolver(%d workers) error = %v", workers, err)
+			}
+			if got.String() != want.String() {
+				t.Errorf("generalSquareSolver(algorithm %d, %d workers) = %q; want %q", algorithm, workers, got, want)
+			}
+		}
+	}
+}
+
+func TestSolveParallelCancelled(t *testing.T) {
+	tetromino1, err := createTestTetromino([]string{
+		"##..",
+		"##..",
+		"....",
+		"....",
+	}, 0)
+	if err != nil {
+		t.Fatalf("ERROR")
+	}
+	tetromino2, err := createTestTetromino([]string{
+		"#...",
+		"###.",
+		"....",
+		"....",
+	}, 1)
+	if err != nil {
+		t.Fatalf("ERROR")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = generalSquareSolver(ctx, []*Tetromino{tetromino1, tetromino2}, WithWorkers(4))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("generalSquareSolver() error = %v; want it to wrap context.Canceled", err)
+	}
+}
@@ -0,0 +1,115 @@
+package solver
+
+// ValidateAndCreatePolyomino creates a polyomino from a KxK block of '#'
+// and '.' holding any number of connected '#' cells. Errors are
+// *ParseError values positioned within the block.
+func ValidateAndCreatePolyomino(block [][]byte, blockNumber int) (*Polyomino, error) {
+	return createPolyomino(block, blockNumber, 0)
+}
+
+// createPolyomino is ValidateAndCreatePolyomino for pieces of exactly cells
+// '#', or of any size when cells is 0.
+func createPolyomino(block [][]byte, blockNumber, cells int) (*Polyomino, error) {
+	size := len(block)
+	if size == 0 {
+		return nil, newParseError(ReasonIncompleteBlock, blockNumber, 0, 0)
+	}
+	if size > maxBoardSize {
+		return nil, newParseError(ReasonRowWidth, blockNumber, 0, maxBoardSize)
+	}
+
+	var (
+		points     []Point
+		minX, maxX = size - 1, 0
+		minY, maxY = size - 1, 0
+	)
+	for y, line := range block {
+		if len(line) != size {
+			return nil, newParseError(ReasonRowWidth, blockNumber, y, min(len(line), size))
+		}
+		for x, char := range line {
+			if char == '#' {
+				if cells > 0 && len(points) == cells {
+					return nil, newParseError(ReasonTooManyCells, blockNumber, y, x)
+				}
+				points = append(points, Point{X: x, Y: y})
+				minX, maxX = min(minX, x), max(maxX, x)
+				minY, maxY = min(minY, y), max(maxY, y)
+			} else if char != '.' {
+				return nil, newParseError(ReasonBadCharacter, blockNumber, y, x)
+			}
+		}
+	}
+
+	if len(points) == 0 || len(points) < cells {
+		return nil, newParseError(ReasonTooFewCells, blockNumber, size-1, size-1)
+	}
+	if i := unreachable(points); i >= 0 {
+		return nil, newParseError(ReasonDisconnected, blockNumber, points[i].Y, points[i].X)
+	}
+
+	// Normalize points to top-left
+	for i := range points {
+		points[i].X -= minX
+		points[i].Y -= minY
+	}
+
+	return &Polyomino{
+		Points: points,
+		Letter: 'A' + rune(blockNumber),
+		Width:  maxX - minX + 1,
+		Height: maxY - minY + 1,
+		shape:  newPieceMask(points),
+	}, nil
+}
+
+// isConnected checks that the points are distinct and edge-connected.
+func isConnected(points []Point) bool {
+	n := len(points)
+	if n == 0 {
+		return false
+	}
+
+	// Check for duplicates
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if points[i] == points[j] {
+				return false
+			}
+		}
+	}
+
+	return unreachable(points) < 0
+}
+
+// unreachable returns the index of the first point that is not
+// edge-connected to points[0], or -1 when every point is.
+func unreachable(points []Point) int {
+	// BFS to check connectivity
+	visited := make([]bool, len(points))
+	queue := []int{0}
+	visited[0] = true
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for i, p := range points {
+			if visited[i] {
+				continue
+			}
+			dx := p.X - points[current].X
+			dy := p.Y - points[current].Y
+			if (dx == 1 || dx == -1) && dy == 0 || dx == 0 && (dy == 1 || dy == -1) {
+				visited[i] = true
+				queue = append(queue, i)
+			}
+		}
+	}
+
+	for i, seen := range visited {
+		if !seen {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,121 @@
+package solver
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestValidateAndCreatePolyomino(t *testing.T) {
+	tests := []struct {
+		name       string
+		block      []string
+		wantErr    bool
+		wantPoints []Point
+		wantWidth  int
+		wantHeight int
+	}{
+		{
+			name:       "Domino",
+			block:      []string{"..", "##"},
+			wantPoints: []Point{{0, 0}, {1, 0}},
+			wantWidth:  2,
+			wantHeight: 1,
+		},
+		{
+			name:       "Tetromino",
+			block:      []string{"##..", "##..", "....", "...."},
+			wantPoints: []Point{{0, 0}, {1, 0}, {0, 1}, {1, 1}},
+			wantWidth:  2,
+			wantHeight: 2,
+		},
+		{
+			name:       "PentominoP",
+			block:      []string{".....", ".##..", ".##..", ".#...", "....."},
+			wantPoints: []Point{{0, 0}, {1, 0}, {0, 1}, {1, 1}, {0, 2}},
+			wantWidth:  2,
+			wantHeight: 3,
+		},
+		{
+			name:    "Empty",
+			block:   []string{"...", "...", "..."},
+			wantErr: true,
+		},
+		{
+			name:    "NotSquare",
+			block:   []string{"###", "..."},
+			wantErr: true,
+		},
+		{
+			name:    "Disconnected",
+			block:   []string{"#.#", "...", "..."},
+			wantErr: true,
+		},
+		{
+			name:    "InvalidCharacter",
+			block:   []string{"#x", ".."},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateAndCreatePolyomino(toByteLines(tt.block), 0)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("ValidateAndCreatePolyomino() error = nil; want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidateAndCreatePolyomino() error = %v; want nil", err)
+			}
+			if !reflect.DeepEqual(got.Points, tt.wantPoints) {
+				t.Errorf("ValidateAndCreatePolyomino() Points = %v; want %v", got.Points, tt.wantPoints)
+			}
+			if got.Width != tt.wantWidth || got.Height != tt.wantHeight {
+				t.Errorf("ValidateAndCreatePolyomino() = %dx%d; want %dx%d", got.Width, got.Height, tt.wantWidth, tt.wantHeight)
+			}
+		})
+	}
+}
+
+func TestIsConnected(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []Point
+		want   bool
+	}{
+		{"Single", []Point{{0, 0}}, true},
+		{"PentominoL", []Point{{0, 0}, {0, 1}, {0, 2}, {0, 3}, {1, 3}}, true},
+		{"Diagonal", []Point{{0, 0}, {1, 1}}, false},
+		{"Duplicate", []Point{{0, 0}, {0, 0}}, false},
+		{"Empty", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isConnected(tt.points); got != tt.want {
+				t.Errorf("isConnected() = %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSolvePentominoFile(t *testing.T) {
+	tetrominos, err := ReadTetrominos(filepath.Join("..", "..", "testfiles", "pentominoes.txt"), WithPolyominoes())
+	if err != nil {
+		t.Fatalf("ReadTetrominos() error = %v", err)
+	}
+	if len(tetrominos) != 4 || totalCells(tetrominos) != 20 {
+		t.Fatalf("ReadTetrominos() = %d pieces of %d cells; want 4 pentominoes", len(tetrominos), totalCells(tetrominos))
+	}
+	got, err := SolveTetrominos(tetrominos)
+	if err != nil {
+		t.Fatalf("SolveTetrominos() error = %v", err)
+	}
+	want := "B.CD.D\nBCCDDD\nB.CAA.\nBBCAA.\n...A..\n......"
+	if !compareBoards(got, want) {
+		t.Errorf("SolveTetrominos() = %q; want %q", got, want)
+	}
+}
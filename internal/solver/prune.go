@@ -0,0 +1,91 @@
+package solver
+
+import "math/bits"
+
+// regionScanner flood-fills the empty cells of a board to find space that no
+// piece can ever use. It keeps its buffers between calls so the search does
+// not allocate on every placement.
+type regionScanner struct {
+	seen  []uint64
+	stack []Point
+
+	smallest int // cells in the smallest piece
+	unit     int // greatest common divisor of the piece sizes
+}
+
+func newRegionScanner(height int, pieces []*Tetromino) *regionScanner {
+	r := &regionScanner{seen: make([]uint64, height)}
+	for i, t := range pieces {
+		if i == 0 {
+			r.smallest, r.unit = len(t.Points), len(t.Points)
+			continue
+		}
+		r.smallest = min(r.smallest, len(t.Points))
+		r.unit = gcd(r.unit, len(t.Points))
+	}
+	return r
+}
+
+// wastedCells returns a lower bound on the empty cells of board that must
+// stay empty. A region smaller than every piece is wasted outright; any
+// other region can only be filled in multiples of the common piece size, so
+// the remainder is wasted. For tetrominos a region of k cells wastes k%4.
+// Counting stops early once the total exceeds limit.
+func (r *regionScanner) wastedCells(board *Board, limit int) int {
+	full := uint64(1)<<uint(board.Width) - 1
+	if board.Width == maxBoardSize {
+		full = ^uint64(0)
+	}
+	copy(r.seen, board.rows)
+
+	wasted := 0
+	for y := 0; y < board.Height; y++ {
+		for r.seen[y]&full != full {
+			x := bits.TrailingZeros64(^r.seen[y])
+			wasted += r.waste(r.fill(board.Width, board.Height, x, y))
+			if wasted > limit {
+				return wasted
+			}
+		}
+	}
+	return wasted
+}
+
+// fill marks the empty region containing (x, y) as seen and returns its size.
+func (r *regionScanner) fill(width, height, x, y int) int {
+	r.seen[y] |= 1 << uint(x)
+	r.stack = append(r.stack[:0], Point{X: x, Y: y})
+	count := 0
+	for len(r.stack) > 0 {
+		p := r.stack[len(r.stack)-1]
+		r.stack = r.stack[:len(r.stack)-1]
+		count++
+		for _, d := range []Point{{0, 1}, {0, -1}, {1, 0}, {-1, 0}} {
+			nx, ny := p.X+d.X, p.Y+d.Y
+			if nx < 0 || ny < 0 || nx >= width || ny >= height || r.seen[ny]&(1<<uint(nx)) != 0 {
+				continue
+			}
+			r.seen[ny] |= 1 << uint(nx)
+			r.stack = append(r.stack, Point{X: nx, Y: ny})
+		}
+	}
+	return count
+}
+
+// waste returns how many cells of a region of the given size must stay empty.
+func (r *regionScanner) waste(size int) int {
+	if size < r.smallest {
+		return size
+	}
+	if r.unit == 0 {
+		return 0
+	}
+	return size % r.unit
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,87 @@
+package solver
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWastedCells(t *testing.T) {
+	tests := []struct {
+		name   string
+		size   int
+		filled []Point
+		limit  int
+		want   int
+	}{
+		{
+			name: "EmptyBoard",
+			size: 4,
+			want: 0,
+		},
+		{
+			name:   "SingleCellPocket",
+			size:   4,
+			filled: []Point{{1, 0}, {0, 1}},
+			limit:  16,
+			want:   1 + 13%4,
+		},
+		{
+			name:   "TwoPockets",
+			size:   3,
+			filled: []Point{{1, 0}, {1, 1}, {1, 2}},
+			limit:  9,
+			want:   3 + 3,
+		},
+		{
+			name:   "StopsAtLimit",
+			size:   3,
+			filled: []Point{{1, 0}, {1, 1}, {1, 2}},
+			limit:  2,
+			want:   3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := NewBoard(tt.size)
+			for i, p := range tt.filled {
+				board.Place(makeTetromino(rune('A'+i), []Point{{0, 0}}), p.X, p.Y)
+			}
+			pieces := []*Tetromino{makeTetromino('T', []Point{{0, 0}, {1, 0}, {2, 0}, {3, 0}})}
+			got := newRegionScanner(tt.size, pieces).wastedCells(board, tt.limit)
+			if got != tt.want {
+				t.Errorf("wastedCells() = %d; want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchSlackCountsOccupiedCells(t *testing.T) {
+	square, err := createTestTetromino([]string{
+		"##..",
+		"##..",
+		"....",
+		"....",
+	}, 0)
+	if err != nil {
+		t.Fatalf("ERROR")
+	}
+	other := *square
+	other.Letter = 'B'
+
+	// With the top half taken the two squares exactly fill the rest, so
+	// the search has no slack and must still find the packing.
+	board := NewBoard(4)
+	board.Place(makeTetromino('X', []Point{{0, 0}, {1, 0}, {2, 0}, {3, 0}, {0, 1}, {1, 1}, {2, 1}, {3, 1}}), 0, 0)
+	s := newSearch(context.Background(), board, []*Tetromino{square, &other})
+	if s.slack != 0 {
+		t.Fatalf("newSearch() slack = %d; want 0", s.slack)
+	}
+	if !s.solve(0) {
+		t.Fatal("solve() = false; want true")
+	}
+	want := "XXXX\nXXXX\nAABB\nAABB"
+	if got := board.String(); got != want {
+		t.Errorf("solve() board = %q; want %q", got, want)
+	}
+}
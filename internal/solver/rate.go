@@ -0,0 +1,92 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+)
+
+// Difficulty grades how hard a puzzle is to solve.
+type Difficulty int
+
+const (
+	Easy Difficulty = iota
+	Medium
+	Hard
+	Extreme
+)
+
+var difficultyNames = [...]string{"easy", "medium", "hard", "extreme"}
+
+func (d Difficulty) String() string {
+	if d < Easy || d > Extreme {
+		return fmt.Sprintf("Difficulty(%d)", int(d))
+	}
+	return difficultyNames[d]
+}
+
+// MarshalText writes the difficulty by name, as in JSON output.
+func (d Difficulty) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// backtrackLimits are the fewest backtracks that make a puzzle Medium,
+// Hard and Extreme.
+var backtrackLimits = [...]int64{100, 10_000, 1_000_000}
+
+// Rating is the outcome of Rate.
+type Rating struct {
+	Difficulty Difficulty `json:"difficulty"`
+	Pieces     int        `json:"pieces"`
+	Width      int        `json:"width"`
+	Height     int        `json:"height"`
+	// Slack is the number of cells the smallest board leaves empty.
+	Slack int   `json:"slack"`
+	Stats Stats `json:"stats"`
+}
+
+// Rate solves the puzzle on its smallest board and grades it by how much
+// searching that took.
+// The search always runs sequentially with backtracking, whatever
+// WithAlgorithm and WithWorkers say, so the same puzzle always gets the
+// same rating.
+//
+// The number of backtracks sets the grade: under 100 is Easy, under 10,000
+// Medium, under 1,000,000 Hard and the rest Extreme. A board with no slack
+// leaves no room for a wrong guess, so it is graded one step harder; one
+// with at least a whole row of slack is graded one step easier.
+func Rate(pieces []*Tetromino, opts ...Option) (*Rating, error) {
+	return RateContext(context.Background(), pieces, opts...)
+}
+
+// RateContext is like Rate but gives up with a *TimeoutError once ctx is
+// cancelled or its deadline passes.
+func RateContext(ctx context.Context, pieces []*Tetromino, opts ...Option) (*Rating, error) {
+	var st Stats
+	// WithMinimalSize keeps the grid layout of repeated pieces from grading
+	// a board larger than the smallest one.
+	opts = append(opts[:len(opts):len(opts)], WithAlgorithm(Backtracking), WithWorkers(1), WithMinimalSize(), WithStats(&st))
+	board, err := SolveBoardContext(ctx, pieces, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Rating{
+		Pieces: len(pieces),
+		Width:  board.Width,
+		Height: board.Height,
+		Slack:  board.Width*board.Height - totalCells(pieces),
+		Stats:  st,
+	}
+	for _, limit := range backtrackLimits {
+		if st.Backtracks >= limit {
+			r.Difficulty++
+		}
+	}
+	switch {
+	case r.Slack == 0 && st.Backtracks > 0 && r.Difficulty < Extreme:
+		r.Difficulty++
+	case r.Slack >= board.Width && r.Difficulty > Easy:
+		r.Difficulty--
+	}
+	return r, nil
+}
@@ -0,0 +1,86 @@
+package solver
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestRate(t *testing.T) {
+	tests := []struct {
+		file string
+		want Difficulty
+	}{
+		{"g00.txt", Easy},
+		{"g01.txt", Easy},
+		{"g03.txt", Medium},
+		{"g04.txt", Hard},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			tetrominos, err := ReadTetrominos(filepath.Join("..", "..", "testfiles", tt.file))
+			if err != nil {
+				t.Fatalf("ReadTetrominos() error = %v", err)
+			}
+			r, err := Rate(tetrominos, WithWorkers(4))
+			if err != nil {
+				t.Fatalf("Rate() error = %v", err)
+			}
+			if r.Difficulty != tt.want {
+				t.Errorf("Rate() = %v with %+v; want %v", r.Difficulty, r, tt.want)
+			}
+			if want := r.Width*r.Height - 4*len(tetrominos); r.Slack != want {
+				t.Errorf("Rate() slack = %d; want %d", r.Slack, want)
+			}
+		})
+	}
+}
+
+func TestRateIsRepeatable(t *testing.T) {
+	tetrominos, err := parseTetrominos(statsPuzzle, newOptions(nil))
+	if err != nil {
+		t.Fatalf("parseTetrominos() error = %v", err)
+	}
+	first, err := Rate(tetrominos)
+	if err != nil {
+		t.Fatalf("Rate() error = %v", err)
+	}
+	second, err := Rate(tetrominos, WithAlgorithm(DancingLinks), WithWorkers(8))
+	if err != nil {
+		t.Fatalf("Rate() error = %v", err)
+	}
+	if first.Difficulty != second.Difficulty || first.Stats.Nodes != second.Stats.Nodes || first.Stats.Backtracks != second.Stats.Backtracks {
+		t.Errorf("Rate() = %+v, then %+v with other engine options; want the same", first, second)
+	}
+}
+
+func TestRateInvalidWidth(t *testing.T) {
+	tetrominos, err := parseTetrominos("####\n....\n....\n....\n", newOptions(nil))
+	if err != nil {
+		t.Fatalf("parseTetrominos() error = %v", err)
+	}
+	if _, err := Rate(tetrominos, WithWidth(2)); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Rate() error = %v; want ErrInvalidOption", err)
+	}
+}
+
+func TestDifficultyString(t *testing.T) {
+	for d, want := range map[Difficulty]string{Easy: "easy", Medium: "medium", Hard: "hard", Extreme: "extreme", 7: "Difficulty(7)"} {
+		if got := d.String(); got != want {
+			t.Errorf("Difficulty(%d).String() = %q; want %q", int(d), got, want)
+		}
+	}
+}
+
+func TestRateRepeatedPieces(t *testing.T) {
+	r, err := Rate(repeatedTs(t))
+	if err != nil {
+		t.Fatalf("Rate() error = %v", err)
+	}
+	if r.Width != 7 || r.Height != 7 || r.Slack != 13 {
+		t.Errorf("Rate() = %dx%d with slack %d; want the smallest board, 7x7 with slack 13", r.Width, r.Height, r.Slack)
+	}
+	if r.Stats.Nodes <= int64(r.Pieces) {
+		t.Errorf("Rate() stats = %+v; want the search of the general solver", r.Stats)
+	}
+}
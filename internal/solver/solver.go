@@ -1,26 +1,46 @@
 package solver
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"math"
+	"math/bits"
 	"sort"
+	"sync"
+	"time"
 )
 
-type ValidationError struct {
-	message string
+func SolveTetrominos(tetrominos []*Tetromino, opts ...Option) (string, error) {
+	return SolveTetrominosContext(context.Background(), tetrominos, opts...)
 }
 
-func (e *ValidationError) Error() string {
-	return e.message
+// SolveTetrominosContext is like SolveTetrominos but gives up with a
+// *TimeoutError once ctx is cancelled or its deadline passes.
+func SolveTetrominosContext(ctx context.Context, tetrominos []*Tetromino, opts ...Option) (string, error) {
+	board, err := SolveBoardContext(ctx, tetrominos, opts...)
+	if err != nil {
+		return "", err
+	}
+	return board.String(), nil
 }
 
-func NewValidationError(message string) error {
-	return &ValidationError{message: message}
+// SolveBoard is like SolveTetrominos but returns the solved board itself.
+func SolveBoard(tetrominos []*Tetromino, opts ...Option) (*Board, error) {
+	return SolveBoardContext(context.Background(), tetrominos, opts...)
 }
 
-func SolveTetrominos(tetrominos []*Tetromino) (string, error) {
+// SolveBoardContext is like SolveBoard but gives up with a *TimeoutError
+// once ctx is cancelled or its deadline passes.
+func SolveBoardContext(ctx context.Context, tetrominos []*Tetromino, opts ...Option) (*Board, error) {
 	if len(tetrominos) == 0 {
-		return "", NewValidationError("ERROR")
+		return nil, NewValidationError(ErrInvalidFormat, "no pieces to solve")
+	}
+
+	o := newOptions(opts)
+	if err := checkWidth(tetrominos, o); err != nil {
+		return nil, err
 	}
 
 	// Assign unique letters to each tetromino
@@ -29,27 +49,55 @@ func SolveTetrominos(tetrominos []*Tetromino) (string, error) {
 	}
 
 	// First try optimized solution for repetitive tetrominos
-	if solution, err := tryOptimizedSquareRepetitiveSolution(tetrominos); err == nil {
-		return solution, nil
+	if o.width == 0 && !o.rectangle && !o.rotate && !o.minimal {
+		start := time.Now()
+		board, err := tryOptimizedSquareRepetitiveSolution(tetrominos, o.trace)
+		elapsed := time.Since(start)
+		st := Stats{Optimized: elapsed}
+		if err == nil {
+			// The grid takes one placement per piece and never backtracks.
+			n := int64(len(tetrominos))
+			st.Nodes, st.Places, st.MaxDepth = n, n, len(tetrominos)
+		}
+		o.stats.add(st)
+		o.log(ctx, slog.LevelInfo, "repetitive layout", "solved", err == nil, "elapsed", elapsed)
+		if err == nil {
+			return board, nil
+		}
 	}
 
 	// Fall back to general solver
-	return generalSquareSolver(tetrominos)
+	start := time.Now()
+	board, err := generalSquareSolver(ctx, tetrominos, opts...)
+	elapsed := time.Since(start)
+	o.stats.add(Stats{General: elapsed})
+	if err != nil {
+		o.log(ctx, slog.LevelInfo, "general solver", "error", err, "elapsed", elapsed)
+		return nil, err
+	}
+	o.log(ctx, slog.LevelInfo, "general solver", "width", board.Width, "height", board.Height, "elapsed", elapsed)
+	return board, nil
 }
 
-func tryOptimizedSquareRepetitiveSolution(tetrominos []*Tetromino) (string, error) {
+// errNotRepetitive tells SolveBoardContext to fall back to the general
+// solver; it never reaches callers.
+var errNotRepetitive = errors.New("pieces are not a grid of one repeated shape")
+
+// tryOptimizedSquareRepetitiveSolution lays five or more copies of one
+// piece out in a grid, reporting each placement to trace when it is set.
+func tryOptimizedSquareRepetitiveSolution(tetrominos []*Tetromino, trace func(Event)) (*Board, error) {
 	groups := groupRepetitiveTetrominos(tetrominos)
 	if len(groups) != 1 || len(groups[0].tetrominos) < 5 {
-		return "", fmt.Errorf("ERROR")
+		return nil, errNotRepetitive
 	}
 
 	t := groups[0].tetrominos[0]
 	n := len(tetrominos)
-	totalBlocks := n * 4
+	totalBlocks := totalCells(tetrominos)
 	minSize := int(math.Ceil(math.Sqrt(float64(totalBlocks))))
 
 	// Try to find the smallest square that can fit all pieces
-	for size := minSize; size <= minSize+5; size++ {
+	for size := minSize; size <= sizeUpperBound(tetrominos); size++ {
 		// Calculate how many pieces fit in rows and columns
 		piecesPerRow := size / t.Width
 		piecesPerCol := size / t.Height
@@ -75,6 +123,9 @@ func tryOptimizedSquareRepetitiveSolution(tetrominos []*Tetromino) (string, erro
 					break
 				}
 				board.Place(tetrominos[placed], x, y)
+				if trace != nil {
+					trace(Event{Kind: EventPlace, Piece: tetrominos[placed], X: x, Y: y, Board: board})
+				}
 				placed++
 			}
 			if !success {
@@ -83,66 +134,421 @@ func tryOptimizedSquareRepetitiveSolution(tetrominos []*Tetromino) (string, erro
 		}
 
 		if success && placed == n {
-			return board.String(), nil
+			return board, nil
+		}
+	}
+
+	return nil, errNotRepetitive
+}
+
+func generalSquareSolver(ctx context.Context, tetrominos []*Tetromino, opts ...Option) (*Board, error) {
+	o := newOptions(opts)
+
+	sortedTetrominos := sortForSearch(tetrominos)
+	orientations := pieceOrientations(sortedTetrominos, o)
+
+	if o.trace != nil {
+		// Events must come from a single backtracking search to be in order.
+		o.algorithm, o.workers = Backtracking, 1
+	}
+
+	// Each run counts into its own Stats, which are summed into total once
+	// it ends so parallel workers never share counters.
+	var (
+		statsMu sync.Mutex
+		total   Stats
+	)
+	record := func(st Stats) {
+		statsMu.Lock()
+		total.add(st)
+		statsMu.Unlock()
+	}
+	defer func() { o.stats.add(total) }()
+
+	// Running searches report their nodes in batches; progress adds them to
+	// the nodes of the boards already finished. Holding statsMu keeps the
+	// hook from running on two workers at once.
+	var (
+		current Progress
+		live    int64
+	)
+	progress := func(nodes int64) {
+		statsMu.Lock()
+		defer statsMu.Unlock()
+		live += nodes
+		p := current
+		p.Nodes += live
+		o.progress(p)
+	}
+
+	newRun := func(ctx context.Context, board *Board) *search {
+		s := newSearch(ctx, board, sortedTetrominos)
+		s.orientations = orientations
+		s.trace = o.trace
+		if o.progress != nil {
+			s.progress = progress
+		}
+		return s
+	}
+	place := func(board *Board) bool {
+		s := newRun(ctx, board)
+		defer func() { record(s.stats) }()
+		return s.solve(0)
+	}
+	branch := func(ctx context.Context, board *Board, t *Tetromino, x, y int) bool {
+		s := newRun(ctx, board)
+		defer func() { record(s.stats) }()
+		return s.solveFrom(t, x, y)
+	}
+	if o.algorithm == DancingLinks {
+		place = func(board *Board) bool {
+			var st Stats
+			defer func() { record(st) }()
+			return solveDLX(ctx, board, orientations, &st)
+		}
+		branch = func(ctx context.Context, board *Board, t *Tetromino, x, y int) bool {
+			board.Place(t, x, y)
+			var st Stats
+			defer func() {
+				// Count the first piece, placed here rather than by the matrix.
+				st.MaxDepth++
+				st.Nodes++
+				st.Places++
+				record(st)
+			}()
+			return solveDLX(ctx, board, orientations[1:], &st)
 		}
 	}
+	if o.workers > 1 {
+		place = func(board *Board) bool {
+			return solveParallel(ctx, board, orientations, o.workers, branch)
+		}
+	}
+
+	// Try solving on boards of increasing size
+	for _, dims := range boardSizes(tetrominos, o) {
+		board := NewRectBoard(dims.width, dims.height)
+		if board == nil {
+			continue
+		}
+		start, nodes := time.Now(), total.Nodes
+		if o.progress != nil {
+			current, live = Progress{Width: dims.width, Height: dims.height, Nodes: nodes}, 0
+			progress(0)
+		}
+		solved := place(board)
+		size := SizeStats{Width: dims.width, Height: dims.height, Nodes: total.Nodes - nodes, Elapsed: time.Since(start), Solved: solved}
+		total.Sizes = append(total.Sizes, size)
+		o.log(ctx, slog.LevelDebug, "board size", "width", size.Width, "height", size.Height, "nodes", size.Nodes, "elapsed", size.Elapsed, "solved", solved)
+		if solved {
+			return board, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, &TimeoutError{Err: err}
+		}
+	}
+	return nil, ErrUnsolvable
+}
+
+// placement is one position of a piece in one of its orientations.
+type placement struct {
+	t    *Tetromino
+	x, y int
+}
 
-	return "", fmt.Errorf("ERROR")
+// placementsOf lists where each orientation of a piece fits on board, in
+// the order the search tries them: by the board cell of the piece's first
+// cell, then by orientation.
+func placementsOf(board *Board, orientations []*Tetromino) []placement {
+	var placements []placement
+	for first := 0; first < board.Width*board.Height; first++ {
+		y := first / board.Width
+		for _, t := range orientations {
+			x := first%board.Width - t.lead()
+			if board.CanPlace(t, x, y) {
+				placements = append(placements, placement{t: t, x: x, y: y})
+			}
+		}
+	}
+	return placements
 }
 
-func generalSquareSolver(tetrominos []*Tetromino) (string, error) {
-	// Sort tetrominos by size and complexity
-	sortedTetrominos := make([]*Tetromino, len(tetrominos))
-	copy(sortedTetrominos, tetrominos)
-	sort.Slice(sortedTetrominos, func(i, j int) bool {
-		areaI := sortedTetrominos[i].Width * sortedTetrominos[i].Height
-		areaJ := sortedTetrominos[j].Width * sortedTetrominos[j].Height
+// pieceOrientations lists the orientations the search may use for each
+// piece: only its own unless rotations are enabled.
+func pieceOrientations(tetrominos []*Tetromino, o *options) [][]*Tetromino {
+	orientations := make([][]*Tetromino, len(tetrominos))
+	for i, t := range tetrominos {
+		if o.rotate {
+			orientations[i] = t.Orientations(o.mirror)
+		} else {
+			orientations[i] = []*Tetromino{t}
+		}
+	}
+	return orientations
+}
+
+// sortForSearch returns a copy of tetrominos ordered by size and
+// complexity, placing the hardest pieces first.
+func sortForSearch(tetrominos []*Tetromino) []*Tetromino {
+	sorted := make([]*Tetromino, len(tetrominos))
+	copy(sorted, tetrominos)
+	sort.Slice(sorted, func(i, j int) bool {
+		areaI := sorted[i].Width * sorted[i].Height
+		areaJ := sorted[j].Width * sorted[j].Height
 		if areaI != areaJ {
 			return areaI > areaJ
 		}
-		return calculateComplexity(sortedTetrominos[i]) > calculateComplexity(sortedTetrominos[j])
+		return calculateComplexity(sorted[i]) > calculateComplexity(sorted[j])
 	})
+	return sorted
+}
 
-	totalBlocks := len(tetrominos) * 4
-	minSize := int(math.Ceil(math.Sqrt(float64(totalBlocks))))
+// boardDims is the width and height of a candidate board.
+type boardDims struct {
+	width, height int
+}
 
-	// Try solving with increasing square board sizes
-	for size := minSize; size <= minSize+5; size++ {
-		board := NewBoard(size)
-		if board == nil {
-			continue
+// boardSizes lists the boards to try, smallest first: squares by default,
+// wells of a fixed width by increasing height, or any rectangle by
+// increasing area. The last entry is always large enough to fit every piece.
+func boardSizes(tetrominos []*Tetromino, o *options) []boardDims {
+	cells := totalCells(tetrominos)
+	// slotWidth and slotHeight bound every piece as placed in its given
+	// orientation, or stood on its narrow side when it may be turned;
+	// minWidth and minHeight are the least room any board must offer.
+	slotWidth, slotHeight := 1, 1
+	minWidth, minHeight := 1, 1
+	for _, t := range tetrominos {
+		w, h := t.Width, t.Height
+		if o.rotate {
+			w, h = min(w, h), max(w, h)
+		}
+		slotWidth = max(slotWidth, w)
+		slotHeight = max(slotHeight, h)
+		minWidth = max(minWidth, w)
+		if o.rotate {
+			minHeight = max(minHeight, w)
+		} else {
+			minHeight = max(minHeight, h)
+		}
+	}
+
+	var sizes []boardDims
+	switch {
+	case o.width > 0:
+		if o.width < minWidth {
+			return nil
+		}
+		perRow := o.width / slotWidth
+		upper := (len(tetrominos) + perRow - 1) / perRow * slotHeight
+		lower := max(minHeight, (cells+o.width-1)/o.width)
+		for h := lower; h <= upper; h++ {
+			sizes = append(sizes, boardDims{o.width, h})
+		}
+	case o.rectangle:
+		bound := sizeUpperBound(tetrominos)
+		for w := minWidth; w <= min(bound*bound/minHeight, maxBoardSize); w++ {
+			for h := max(minHeight, (cells+w-1)/w); w*h <= bound*bound; h++ {
+				sizes = append(sizes, boardDims{w, h})
+			}
+		}
+		// Among equal areas prefer the squarer board, then the wider one.
+		sort.Slice(sizes, func(i, j int) bool {
+			a, b := sizes[i], sizes[j]
+			if a.width*a.height != b.width*b.height {
+				return a.width*a.height < b.width*b.height
+			}
+			if max(a.width, a.height) != max(b.width, b.height) {
+				return max(a.width, a.height) < max(b.width, b.height)
+			}
+			return a.width > b.width
+		})
+	default:
+		minSize := int(math.Ceil(math.Sqrt(float64(cells))))
+		for size := minSize; size <= sizeUpperBound(tetrominos); size++ {
+			sizes = append(sizes, boardDims{size, size})
+		}
+	}
+	return sizes
+}
+
+// checkWidth rejects a WithWidth well that no board can offer or that is
+// narrower than a piece, which boardSizes would otherwise turn into
+// ErrUnsolvable.
+func checkWidth(tetrominos []*Tetromino, o *options) error {
+	if o.width <= 0 {
+		return nil
+	}
+	if o.width > maxBoardSize {
+		return NewValidationError(ErrInvalidOption, fmt.Sprintf("width %d is over the maximum of %d", o.width, maxBoardSize))
+	}
+	for _, t := range tetrominos {
+		w := t.Width
+		if o.rotate {
+			w = min(t.Width, t.Height)
 		}
-		if solve(board, sortedTetrominos, 0) {
-			return board.String(), nil
+		if w > o.width {
+			return NewValidationError(ErrInvalidOption, fmt.Sprintf("width %d is narrower than a piece %d wide", o.width, w))
+		}
+	}
+	return nil
+}
+
+// totalCells returns the number of blocks in all pieces together.
+func totalCells(tetrominos []*Tetromino) int {
+	cells := 0
+	for _, t := range tetrominos {
+		cells += len(t.Points)
+	}
+	return cells
+}
+
+// sizeUpperBound returns a board size that always fits every piece: one
+// slot per piece, each as large as the biggest piece, laid out in a square.
+func sizeUpperBound(tetrominos []*Tetromino) int {
+	slot := 1
+	for _, t := range tetrominos {
+		slot = max(slot, max(t.Width, t.Height))
+	}
+	perSide := int(math.Ceil(math.Sqrt(float64(len(tetrominos)))))
+	return perSide * slot
+}
+
+// search holds the state of one backtracking run on a board.
+type search struct {
+	ctx          context.Context
+	board        *Board
+	tetrominos   []*Tetromino
+	orientations [][]*Tetromino // ways each piece may be placed
+	regions      *regionScanner
+	slack        int // empty cells the board can afford to leave
+
+	// Identical pieces are interchangeable, so each copy is only placed
+	// after the position of the copy before it.
+	previous []int // index of the previous identical piece, or -1
+	anchors  []int // y*width+x of the first cell of each placed piece
+
+	// onSolution, when set, is called for every complete packing instead of
+	// stopping at the first; returning false ends the search.
+	onSolution func(*Board) bool
+
+	// trace, when set, is told about every placement and removal.
+	trace func(Event)
+
+	stats Stats
+	depth int // pieces on the board
+
+	// progress, when set, is told every progressEvery nodes.
+	progress func(nodes int64)
+}
+
+// progressEvery is how many nodes a search tries between progress reports.
+const progressEvery = 1 << 12
+
+func newSearch(ctx context.Context, board *Board, tetrominos []*Tetromino) *search {
+	slack := board.Width*board.Height - totalCells(tetrominos)
+	for _, row := range board.rows {
+		slack -= bits.OnesCount64(row)
+	}
+	previous := make([]int, len(tetrominos))
+	for _, g := range groupRepetitiveTetrominos(tetrominos) {
+		previous[g.indices[0]] = -1
+		for k := 1; k < len(g.indices); k++ {
+			previous[g.indices[k]] = g.indices[k-1]
 		}
 	}
-	return "", fmt.Errorf("ERROR")
+	return &search{
+		ctx:          ctx,
+		board:        board,
+		tetrominos:   tetrominos,
+		orientations: pieceOrientations(tetrominos, &options{}),
+		regions:      newRegionScanner(board.Height, tetrominos),
+		slack:        slack,
+		previous:     previous,
+		anchors:      make([]int, len(tetrominos)),
+	}
+}
+
+// solveFrom searches with the first piece fixed at (x, y) in orientation t.
+func (s *search) solveFrom(t *Tetromino, x, y int) bool {
+	s.place(t, x, y)
+	s.anchors[0] = y*s.board.Width + x + t.lead()
+	return s.solve(1)
 }
 
-func solve(board *Board, tetrominos []*Tetromino, index int) bool {
-	if index == len(tetrominos) {
-		return true
+// solve places the pieces from index on and reports whether the search
+// should stop: a packing was found, or onSolution asked to stop.
+func (s *search) solve(index int) bool {
+	if index == len(s.tetrominos) {
+		return s.onSolution == nil || !s.onSolution(s.board)
+	}
+	if s.ctx.Err() != nil {
+		return false
 	}
 
-	t := tetrominos[index]
-	for y := 0; y <= board.Size-t.Height; y++ {
-		for x := 0; x <= board.Size-t.Width; x++ {
+	board := s.board
+	last := index == len(s.tetrominos)-1
+	start := 0
+	if p := s.previous[index]; p >= 0 {
+		start = s.anchors[p] + 1
+	}
+	// Walk the cells in order and try each orientation with its first cell
+	// there. Searching by first cell keeps the symmetry breaking from
+	// changing which packing is found first.
+	for first := start; first < board.Width*board.Height; first++ {
+		y := first / board.Width
+		for _, t := range s.orientations[index] {
+			x := first%board.Width - t.lead()
 			if !board.CanPlace(t, x, y) {
 				continue
 			}
 
-			board.Place(t, x, y)
-			if solve(board, tetrominos, index+1) {
-				return true
+			s.place(t, x, y)
+			s.anchors[index] = first
+			// Skip the subtree when the pockets just created can never be filled.
+			if last || s.regions.wastedCells(board, s.slack) <= s.slack {
+				if s.solve(index + 1) {
+					return true
+				}
+			} else {
+				s.stats.Pruned++
 			}
-			board.Remove(t, x, y)
+			s.remove(t, x, y)
 		}
 	}
 	return false
 }
 
+// place puts t on the board at (x, y), counts it and reports it to the
+// trace hook.
+func (s *search) place(t *Tetromino, x, y int) {
+	s.board.Place(t, x, y)
+	s.depth++
+	s.stats.enter(s.depth)
+	s.stats.Places++
+	if s.progress != nil && s.stats.Nodes%progressEvery == 0 {
+		s.progress(progressEvery)
+	}
+	if s.trace != nil {
+		s.trace(Event{Kind: EventPlace, Piece: t, X: x, Y: y, Board: s.board})
+	}
+}
+
+// remove takes t off the board at (x, y), counts the backtrack and reports
+// it to the trace hook.
+func (s *search) remove(t *Tetromino, x, y int) {
+	s.board.Remove(t, x, y)
+	s.depth--
+	s.stats.Backtracks++
+	s.stats.Removes++
+	if s.trace != nil {
+		s.trace(Event{Kind: EventRemove, Piece: t, X: x, Y: y, Board: s.board})
+	}
+}
+
 type tetrominoGroup struct {
 	tetrominos []*Tetromino
+	indices    []int // positions of the group's tetrominos in the input
 	points     []Point
 }
 
@@ -154,10 +560,11 @@ func groupRepetitiveTetrominos(tetrominos []*Tetromino) []tetrominoGroup {
 		if used[i] {
 			continue
 		}
-		group := tetrominoGroup{tetrominos: []*Tetromino{t1}, points: t1.Points}
+		group := tetrominoGroup{tetrominos: []*Tetromino{t1}, indices: []int{i}, points: t1.Points}
 		for j := i + 1; j < len(tetrominos); j++ {
 			if !used[j] && areTetrominosEqual(t1, tetrominos[j]) {
 				group.tetrominos = append(group.tetrominos, tetrominos[j])
+				group.indices = append(group.indices, j)
 				used[j] = true
 			}
 		}
@@ -235,4 +642,4 @@ func max(a, b int) int {
 		return a
 	}
 	return b
-}
\ No newline at end of file
+}
@@ -1,6 +1,9 @@
 package solver
 
 import (
+	"context"
+	"errors"
+	"reflect"
 	"testing"
 )
 
@@ -26,7 +29,7 @@ func TestSolveTetrominos(t *testing.T) {
 			name:       "EmptyInput",
 			tetrominos: []*Tetromino{},
 			wantErr:    true,
-			wantErrMsg: "ERROR",
+			wantErrMsg: "no pieces to solve",
 		},
 		{
 			name:       "SingleSquareTetromino",
@@ -78,7 +81,7 @@ func TestTryOptimizedSquareRepetitiveSolution(t *testing.T) {
 			name:       "SingleTetromino",
 			tetrominos: []*Tetromino{tetromino},
 			wantErr:    true,
-			wantErrMsg: "ERROR",
+			wantErrMsg: errNotRepetitive.Error(),
 		},
 		{
 			name: "FiveIdenticalTetrominos",
@@ -91,7 +94,7 @@ func TestTryOptimizedSquareRepetitiveSolution(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := tryOptimizedSquareRepetitiveSolution(tt.tetrominos)
+			got, err := tryOptimizedSquareRepetitiveSolution(tt.tetrominos, nil)
 			if tt.wantErr {
 				if err == nil {
 					t.Errorf("tryOptimizedSquareRepetitiveSolution() error = nil; want %q", tt.wantErrMsg)
@@ -101,9 +104,9 @@ func TestTryOptimizedSquareRepetitiveSolution(t *testing.T) {
 				return
 			}
 			if err != nil {
-				t.Errorf("tryOptimizedSquareRepetitiveSolution() error = %v; want nil", err)
+				t.Fatalf("tryOptimizedSquareRepetitiveSolution() error = %v; want nil", err)
 			}
-			if !compareBoards(got, tt.wantBoard) {
+			if !compareBoards(got.String(), tt.wantBoard) {
 				t.Errorf("tryOptimizedSquareRepetitiveSolution() = %q; want %q", got, tt.wantBoard)
 			}
 		})
@@ -145,7 +148,7 @@ func TestGeneralSquareSolver(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := generalSquareSolver(tt.tetrominos)
+			got, err := generalSquareSolver(context.Background(), tt.tetrominos)
 			if tt.wantErr {
 				if err == nil {
 					t.Errorf("generalSquareSolver() error = nil; want error")
@@ -153,9 +156,9 @@ func TestGeneralSquareSolver(t *testing.T) {
 				return
 			}
 			if err != nil {
-				t.Errorf("generalSquareSolver() error = %v; want nil", err)
+				t.Fatalf("generalSquareSolver() error = %v; want nil", err)
 			}
-			if !compareBoards(got, tt.wantBoard) {
+			if !compareBoards(got.String(), tt.wantBoard) {
 				t.Errorf("generalSquareSolver() = %q; want %q", got, tt.wantBoard)
 			}
 		})
@@ -255,3 +258,337 @@ func TestAreTetrominosEqual(t *testing.T) {
 		})
 	}
 }
+
+func TestSolveTetrominosContextCancelled(t *testing.T) {
+	tetromino1, err := createTestTetromino([]string{
+		"##..",
+		"##..",
+		"....",
+		"....",
+	}, 0)
+	if err != nil {
+		t.Fatalf("ERROR")
+	}
+	tetromino2, err := createTestTetromino([]string{
+		"#...",
+		"###.",
+		"....",
+		"....",
+	}, 1)
+	if err != nil {
+		t.Fatalf("ERROR")
+	}
+
+	for _, algorithm := range []Algorithm{Backtracking, DancingLinks} {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := SolveTetrominosContext(ctx, []*Tetromino{tetromino1, tetromino2}, WithAlgorithm(algorithm))
+		var timeoutErr *TimeoutError
+		if !errors.As(err, &timeoutErr) {
+			t.Fatalf("SolveTetrominosContext(algorithm %d) error = %v; want *TimeoutError", algorithm, err)
+		}
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("SolveTetrominosContext(algorithm %d) error = %v; want it to wrap context.Canceled", algorithm, err)
+		}
+	}
+}
+
+func TestSearchSymmetryBreaking(t *testing.T) {
+	shapes := [][]string{
+		{"###.", ".#..", "....", "...."},
+		{"##..", "##..", "....", "...."},
+		{"###.", ".#..", "....", "...."},
+		{"#...", "###.", "....", "...."},
+		{"###.", ".#..", "....", "...."},
+		{"###.", ".#..", "....", "...."},
+		{"##..", "##..", "....", "...."},
+		{"###.", ".#..", "....", "...."},
+	}
+	var tetrominos []*Tetromino
+	for i, shape := range shapes {
+		tetromino, err := createTestTetromino(shape, i)
+		if err != nil {
+			t.Fatalf("ERROR")
+		}
+		tetrominos = append(tetrominos, tetromino)
+	}
+
+	s := newSearch(context.Background(), NewBoard(7), tetrominos)
+	wantPrevious := []int{-1, -1, 0, -1, 2, 4, 1, 5}
+	if !reflect.DeepEqual(s.previous, wantPrevious) {
+		t.Errorf("newSearch() previous = %v; want %v", s.previous, wantPrevious)
+	}
+
+	// Breaking the symmetry must not change which packing is found first.
+	unbroken := newSearch(context.Background(), NewBoard(7), tetrominos)
+	for i := range unbroken.previous {
+		unbroken.previous[i] = -1
+	}
+	if !s.solve(0) || !unbroken.solve(0) {
+		t.Fatal("solve() = false; want true")
+	}
+	if got, want := s.board.String(), unbroken.board.String(); got != want {
+		t.Errorf("solve() with symmetry breaking = %q; want %q", got, want)
+	}
+}
+
+func TestSizeUpperBound(t *testing.T) {
+	square, err := createTestTetromino([]string{
+		"##..",
+		"##..",
+		"....",
+		"....",
+	}, 0)
+	if err != nil {
+		t.Fatalf("ERROR")
+	}
+	bar, err := createTestTetromino([]string{
+		"#...",
+		"#...",
+		"#...",
+		"#...",
+	}, 1)
+	if err != nil {
+		t.Fatalf("ERROR")
+	}
+
+	tests := []struct {
+		name       string
+		tetrominos []*Tetromino
+		want       int
+	}{
+		{"OneSquare", []*Tetromino{square}, 2},
+		{"FiveSquares", []*Tetromino{square, square, square, square, square}, 6},
+		{"SquareAndBar", []*Tetromino{square, bar}, 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sizeUpperBound(tt.tetrominos); got != tt.want {
+				t.Errorf("sizeUpperBound() = %d; want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeneralSquareSolverUnsolvable(t *testing.T) {
+	// The declared bounds understate the piece, so no board up to the
+	// bound can hold it.
+	wide := &Tetromino{
+		Points: []Point{{0, 0}, {1, 0}, {2, 0}, {3, 0}},
+		Letter: 'A',
+		Width:  1,
+		Height: 1,
+	}
+
+	_, err := generalSquareSolver(context.Background(), []*Tetromino{wide})
+	if !errors.Is(err, ErrUnsolvable) {
+		t.Errorf("generalSquareSolver() error = %v; want %v", err, ErrUnsolvable)
+	}
+}
+
+func TestSolveTetrominosRectangular(t *testing.T) {
+	newPiece := func(shape []string, id int) *Tetromino {
+		tetromino, err := createTestTetromino(shape, id)
+		if err != nil {
+			t.Fatalf("ERROR")
+		}
+		return tetromino
+	}
+	pieces := func() []*Tetromino {
+		return []*Tetromino{
+			newPiece([]string{"##..", "##..", "....", "...."}, 0),
+			newPiece([]string{"#...", "###.", "....", "...."}, 1),
+			newPiece([]string{"####", "....", "....", "...."}, 2),
+		}
+	}
+
+	tests := []struct {
+		name      string
+		opts      []Option
+		wantBoard string
+		wantErr   error
+	}{
+		{
+			name:      "Well",
+			opts:      []Option{WithWidth(6)},
+			wantBoard: "BCCCC.\nBBBAA.\n...AA.",
+		},
+		{
+			name:      "SmallestRectangle",
+			opts:      []Option{WithRectangle()},
+			wantBoard: "BCCCCAA\nBBB..AA",
+		},
+		{
+			name:    "WellTooNarrow",
+			opts:    []Option{WithWidth(3)},
+			wantErr: ErrInvalidOption,
+		},
+		{
+			name:    "WellTooWide",
+			opts:    []Option{WithWidth(MaxWidth + 1)},
+			wantErr: ErrInvalidOption,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SolveTetrominos(pieces(), tt.opts...)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("SolveTetrominos() error = %v; want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SolveTetrominos() error = %v; want nil", err)
+			}
+			if !compareBoards(got, tt.wantBoard) {
+				t.Errorf("SolveTetrominos() = %q; want %q", got, tt.wantBoard)
+			}
+		})
+	}
+}
+
+func TestBoardSizes(t *testing.T) {
+	square, err := createTestTetromino([]string{
+		"##..",
+		"##..",
+		"....",
+		"....",
+	}, 0)
+	if err != nil {
+		t.Fatalf("ERROR")
+	}
+	tetrominos := []*Tetromino{square, square, square}
+
+	squares := boardSizes(tetrominos, newOptions(nil))
+	if want := []boardDims{{4, 4}}; !reflect.DeepEqual(squares, want) {
+		t.Errorf("boardSizes() squares = %v; want %v", squares, want)
+	}
+
+	wells := boardSizes(tetrominos, newOptions([]Option{WithWidth(5)}))
+	if want := []boardDims{{5, 3}, {5, 4}}; !reflect.DeepEqual(wells, want) {
+		t.Errorf("boardSizes() wells = %v; want %v", wells, want)
+	}
+
+	rects := boardSizes(tetrominos, newOptions([]Option{WithRectangle()}))
+	if rects[0] != (boardDims{4, 3}) {
+		t.Errorf("boardSizes() first rectangle = %v; want {4 3}", rects[0])
+	}
+	last := rects[len(rects)-1]
+	if last.width*last.height != 16 {
+		t.Errorf("boardSizes() last rectangle = %v; want area 16", last)
+	}
+}
+
+func TestSolveTetrominosWithRotations(t *testing.T) {
+	newPieces := func(shape []string, n int) []*Tetromino {
+		var tetrominos []*Tetromino
+		for i := 0; i < n; i++ {
+			tetromino, err := createTestTetromino(shape, i)
+			if err != nil {
+				t.Fatalf("ERROR")
+			}
+			tetrominos = append(tetrominos, tetromino)
+		}
+		return tetrominos
+	}
+	l := []string{"#...", "#...", "##..", "...."}
+	j := []string{"#...", "###.", "....", "...."}
+
+	tests := []struct {
+		name       string
+		tetrominos []*Tetromino
+		opts       []Option
+		wantBoard  string
+	}{
+		{
+			name:       "FixedL",
+			tetrominos: newPieces(l, 2),
+			wantBoard:  "A.B.\nA.B.\nAABB\n....",
+		},
+		{
+			name:       "TurnedL",
+			tetrominos: newPieces(l, 2),
+			opts:       []Option{WithRotations(false)},
+			wantBoard:  "ABB\nA.B\nAAB",
+		},
+		{
+			name:       "TurnedJ",
+			tetrominos: newPieces(j, 4),
+			opts:       []Option{WithRotations(false)},
+			wantBoard:  "ABBB\nAAAB\nCDDD\nCCCD",
+		},
+		{
+			name:       "TurnedJDancingLinks",
+			tetrominos: newPieces(j, 4),
+			opts:       []Option{WithRotations(false), WithAlgorithm(DancingLinks)},
+			wantBoard:  "ABBB\nAAAB\nCDDD\nCCCD",
+		},
+		{
+			name:       "TurnedJParallel",
+			tetrominos: newPieces(j, 4),
+			opts:       []Option{WithRotations(true), WithWorkers(4)},
+			wantBoard:  "ABBB\nAAAB\nCDDD\nCCCD",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SolveTetrominos(tt.tetrominos, tt.opts...)
+			if err != nil {
+				t.Fatalf("SolveTetrominos() error = %v; want nil", err)
+			}
+			if !compareBoards(got, tt.wantBoard) {
+				t.Errorf("SolveTetrominos() = %q; want %q", got, tt.wantBoard)
+			}
+		})
+	}
+}
+
+func TestSolveBoardWithTrace(t *testing.T) {
+	tetrominos := make([]*Tetromino, 3)
+	for i, rows := range [][]string{
+		{"#...", "#...", "#...", "#..."},
+		{"##..", "##..", "....", "...."},
+		{".#..", "###.", "....", "...."},
+	} {
+		var err error
+		if tetrominos[i], err = createTestTetromino(rows, i); err != nil {
+			t.Fatalf("ERROR")
+		}
+	}
+
+	want, err := SolveTetrominos(tetrominos)
+	if err != nil {
+		t.Fatalf("SolveTetrominos() error = %v", err)
+	}
+
+	for _, opts := range [][]Option{nil, {WithAlgorithm(DancingLinks), WithWorkers(4)}} {
+		placed := 0
+		var last *Board
+		trace := func(e Event) {
+			if e.Kind == EventPlace {
+				placed++
+			} else {
+				placed--
+			}
+			if e.Board.Placed < 0 || e.Board.Placed != placed {
+				t.Fatalf("event %v: board holds %d pieces; want %d", e.Kind, e.Board.Placed, placed)
+			}
+			last = e.Board
+		}
+		got, err := SolveBoard(tetrominos, append(opts, WithTrace(trace))...)
+		if err != nil {
+			t.Fatalf("SolveBoard() error = %v", err)
+		}
+		if got.String() != want {
+			t.Errorf("SolveBoard() with trace = %q; want %q", got, want)
+		}
+		if placed != len(tetrominos) || last != got {
+			t.Errorf("trace ended with %d pieces placed; want %d on the returned board", placed, len(tetrominos))
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package solver
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// Stats counts the work a search did. Pass one to WithStats to fill it in.
+// Timings are left out of JSON so equal searches always encode the same.
+type Stats struct {
+	// Nodes is the number of placements tried, one per search node.
+	Nodes int64 `json:"nodes"`
+	// Backtracks is the number of placements taken back because nothing
+	// fitted after them.
+	Backtracks int64 `json:"backtracks"`
+	// MaxDepth is the most pieces that were on the board at once.
+	MaxDepth int `json:"maxDepth"`
+
+	// Places and Removes count the pieces put on and taken off boards.
+	Places  int64 `json:"places"`
+	Removes int64 `json:"removes"`
+	// Pruned counts the branches cut because the pieces left could not
+	// fill the board: pockets too small for any piece, or a piece with
+	// nowhere to go.
+	Pruned int64 `json:"pruned"`
+
+	// Sizes lists each board the general solver tried, in order.
+	Sizes []SizeStats `json:"-"`
+	// Optimized is the time spent laying out repeated pieces in a grid and
+	// General the time spent in the general solver.
+	Optimized time.Duration `json:"-"`
+	General   time.Duration `json:"-"`
+}
+
+// SizeStats is the search on one board size.
+type SizeStats struct {
+	Width, Height int
+	Nodes         int64
+	Elapsed       time.Duration
+	Solved        bool
+}
+
+// Progress tells a WithProgress hook how far the solver has got.
+type Progress struct {
+	Width, Height int   // board being searched
+	Nodes         int64 // placements tried so far, over every board
+}
+
+// add folds the counts of another run into s, which may be nil.
+func (s *Stats) add(other Stats) {
+	if s == nil {
+		return
+	}
+	s.Nodes += other.Nodes
+	s.Backtracks += other.Backtracks
+	s.MaxDepth = max(s.MaxDepth, other.MaxDepth)
+	s.Places += other.Places
+	s.Removes += other.Removes
+	s.Pruned += other.Pruned
+	s.Sizes = append(s.Sizes, other.Sizes...)
+	s.Optimized += other.Optimized
+	s.General += other.General
+}
+
+// enter records a placement that leaves depth pieces on the board.
+func (s *Stats) enter(depth int) {
+	s.Nodes++
+	s.MaxDepth = max(s.MaxDepth, depth)
+}
+
+// WriteTo writes the statistics as one figure per line, followed by a
+// line for each board size tried.
+func (s *Stats) WriteTo(w io.Writer) (int64, error) {
+	var n int64
+	printf := func(format string, args ...any) error {
+		k, err := fmt.Fprintf(w, format, args...)
+		n += int64(k)
+		return err
+	}
+	lines := []struct {
+		name  string
+		value any
+	}{
+		{"nodes", s.Nodes},
+		{"places", s.Places},
+		{"removes", s.Removes},
+		{"backtracks", s.Backtracks},
+		{"pruned", s.Pruned},
+		{"max depth", s.MaxDepth},
+		{"optimized", s.Optimized},
+		{"general", s.General},
+	}
+	for _, l := range lines {
+		if err := printf("%-11s %v\n", l.name, l.value); err != nil {
+			return n, err
+		}
+	}
+	for _, size := range s.Sizes {
+		result := "no packing"
+		if size.Solved {
+			result = "solved"
+		}
+		board := fmt.Sprintf("board %dx%d", size.Width, size.Height)
+		if err := printf("%-11s %d nodes in %v, %s\n", board, size.Nodes, size.Elapsed, result); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,192 @@
+package solver
+
+import (
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const statsPuzzle = "#...\n#...\n#...\n#...\n\n....\n....\n..##\n..##\n\n.###\n...#\n....\n....\n\n....\n..##\n.##.\n....\n"
+
+func TestStatsMatchTrace(t *testing.T) {
+	tetrominos, err := parseTetrominos(statsPuzzle, newOptions(nil))
+	if err != nil {
+		t.Fatalf("parseTetrominos() error = %v", err)
+	}
+	var st Stats
+	var places, removes int64
+	trace := func(e Event) {
+		if e.Kind == EventPlace {
+			places++
+		} else {
+			removes++
+		}
+	}
+	if _, err := SolveBoard(tetrominos, WithStats(&st), WithTrace(trace)); err != nil {
+		t.Fatalf("SolveBoard() error = %v", err)
+	}
+	if st.Nodes != places || st.Places != places || st.Backtracks != removes || st.Removes != removes {
+		t.Errorf("Stats = %+v; want %d nodes and places and %d backtracks and removes as traced", st, places, removes)
+	}
+	if st.MaxDepth != len(tetrominos) {
+		t.Errorf("Stats.MaxDepth = %d; want %d", st.MaxDepth, len(tetrominos))
+	}
+	if st.Backtracks == 0 {
+		t.Error("Stats.Backtracks = 0; want the search to have backtracked")
+	}
+}
+
+func TestStatsEngines(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		opts []Option
+	}{
+		{"dancing links", []Option{WithAlgorithm(DancingLinks)}},
+		{"parallel", []Option{WithWorkers(4)}},
+		{"parallel dancing links", []Option{WithAlgorithm(DancingLinks), WithWorkers(4)}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tetrominos, err := parseTetrominos(statsPuzzle, newOptions(nil))
+			if err != nil {
+				t.Fatalf("parseTetrominos() error = %v", err)
+			}
+			var st Stats
+			if _, err := SolveBoard(tetrominos, append(tt.opts, WithStats(&st))...); err != nil {
+				t.Fatalf("SolveBoard() error = %v", err)
+			}
+			if st.Nodes < int64(len(tetrominos)) || st.MaxDepth != len(tetrominos) {
+				t.Errorf("Stats = %+v; want at least %d nodes reaching depth %d", st, len(tetrominos), len(tetrominos))
+			}
+		})
+	}
+}
+
+func TestStatsRepetitive(t *testing.T) {
+	puzzle := strings.Repeat("##..\n##..\n....\n....\n\n", 5)
+	tetrominos, err := parseTetrominos(strings.TrimSuffix(puzzle, "\n"), newOptions(nil))
+	if err != nil {
+		t.Fatalf("parseTetrominos() error = %v", err)
+	}
+	var st Stats
+	if _, err := SolveBoard(tetrominos, WithStats(&st)); err != nil {
+		t.Fatalf("SolveBoard() error = %v", err)
+	}
+	if st.Nodes != 5 || st.Places != 5 || st.MaxDepth != 5 || st.Backtracks != 0 {
+		t.Errorf("Stats = %+v; want 5 nodes and places at depth 5 with no backtracks", st)
+	}
+	if st.Optimized == 0 || st.General != 0 || len(st.Sizes) != 0 {
+		t.Errorf("Stats = %+v; want time in the repetitive layout only", st)
+	}
+}
+
+func TestStatsSizes(t *testing.T) {
+	tetrominos, err := parseTetrominos(statsPuzzle, newOptions(nil))
+	if err != nil {
+		t.Fatalf("parseTetrominos() error = %v", err)
+	}
+	var st Stats
+	board, err := SolveBoard(tetrominos, WithStats(&st))
+	if err != nil {
+		t.Fatalf("SolveBoard() error = %v", err)
+	}
+	if len(st.Sizes) == 0 {
+		t.Fatal("Stats.Sizes is empty; want every board size tried")
+	}
+	var nodes int64
+	for i, size := range st.Sizes {
+		nodes += size.Nodes
+		if last := i == len(st.Sizes)-1; size.Solved != last {
+			t.Errorf("Stats.Sizes[%d].Solved = %v; want %v", i, size.Solved, last)
+		}
+	}
+	if last := st.Sizes[len(st.Sizes)-1]; last.Width != board.Width || last.Height != board.Height {
+		t.Errorf("last size = %dx%d; want the solved %dx%d", last.Width, last.Height, board.Width, board.Height)
+	}
+	if nodes != st.Nodes {
+		t.Errorf("board sizes sum to %d nodes; want %d", nodes, st.Nodes)
+	}
+	if st.General == 0 {
+		t.Error("Stats.General = 0; want the general solver timed")
+	}
+	if st.Pruned == 0 {
+		t.Error("Stats.Pruned = 0; want pruned branches counted")
+	}
+}
+
+func TestStatsWriteTo(t *testing.T) {
+	st := Stats{Nodes: 12, Places: 12, Removes: 8, Backtracks: 8, Pruned: 3, MaxDepth: 4, General: time.Millisecond,
+		Sizes: []SizeStats{{Width: 4, Height: 4, Nodes: 12, Elapsed: time.Millisecond, Solved: true}}}
+	var b strings.Builder
+	n, err := st.WriteTo(&b)
+	if err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	want := "nodes       12\nplaces      12\nremoves     8\nbacktracks  8\npruned      3\nmax depth   4\n" +
+		"optimized   0s\ngeneral     1ms\nboard 4x4   12 nodes in 1ms, solved\n"
+	if got := b.String(); got != want || n != int64(len(want)) {
+		t.Errorf("WriteTo() = %d, %q; want %d, %q", n, got, len(want), want)
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	tetrominos, err := parseTetrominos(statsPuzzle, newOptions(nil))
+	if err != nil {
+		t.Fatalf("parseTetrominos() error = %v", err)
+	}
+	for _, tt := range []struct {
+		level slog.Level
+		want  []string
+	}{
+		{slog.LevelInfo, []string{"msg=\"general solver\""}},
+		{slog.LevelDebug, []string{"msg=\"board size\"", "msg=\"general solver\""}},
+	} {
+		var b strings.Builder
+		logger := slog.New(slog.NewTextHandler(&b, &slog.HandlerOptions{Level: tt.level}))
+		if _, err := SolveBoard(clonePieces(tetrominos), WithLogger(logger)); err != nil {
+			t.Fatalf("SolveBoard() error = %v", err)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(b.String(), want) {
+				t.Errorf("log at %v = %q; want it to contain %s", tt.level, b.String(), want)
+			}
+		}
+		if tt.level == slog.LevelInfo && strings.Contains(b.String(), "board size") {
+			t.Errorf("log at %v = %q; want no board sizes", tt.level, b.String())
+		}
+	}
+}
+
+func TestWithProgress(t *testing.T) {
+	for _, workers := range []int{1, 4} {
+		tetrominos, err := ReadTetrominos(filepath.Join("..", "..", "testfiles", "g04.txt"))
+		if err != nil {
+			t.Fatalf("ReadTetrominos() error = %v", err)
+		}
+		var (
+			st      Stats
+			reports []Progress
+		)
+		board, err := SolveBoard(tetrominos, WithWorkers(workers), WithStats(&st), WithProgress(func(p Progress) {
+			reports = append(reports, p)
+		}))
+		if err != nil {
+			t.Fatalf("SolveBoard() error = %v", err)
+		}
+		if len(reports) < 2 {
+			t.Fatalf("%d workers: %d progress reports; want the board size and some nodes", workers, len(reports))
+		}
+		for i, p := range reports {
+			if i > 0 && p.Nodes < reports[i-1].Nodes {
+				t.Errorf("%d workers: report %d = %+v after %+v; want nodes never to fall", workers, i, p, reports[i-1])
+			}
+			if p.Nodes > st.Nodes {
+				t.Errorf("%d workers: report %d = %+v; want at most the %d nodes searched", workers, i, p, st.Nodes)
+			}
+		}
+		if last := reports[len(reports)-1]; last.Width != board.Width || last.Height != board.Height {
+			t.Errorf("%d workers: last report = %+v; want the solved %dx%d board", workers, last, board.Width, board.Height)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package solver
+
+import (
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strings"
+)
+
+// SVGOptions configures RenderSVG.
+type SVGOptions struct {
+	CellSize int  // side of one cell in pixels; 40 when zero
+	Labels   bool // write each piece's letter on its first cell
+}
+
+const (
+	defaultCellSize = 40
+	outlineWidth    = 3
+)
+
+type svgDoc struct {
+	XMLName  xml.Name `xml:"svg"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Width    int      `xml:"width,attr"`
+	Height   int      `xml:"height,attr"`
+	ViewBox  string   `xml:"viewBox,attr"`
+	Elements []any
+}
+
+type svgRect struct {
+	XMLName xml.Name `xml:"rect"`
+	X       int      `xml:"x,attr"`
+	Y       int      `xml:"y,attr"`
+	Width   int      `xml:"width,attr"`
+	Height  int      `xml:"height,attr"`
+	Fill    string   `xml:"fill,attr"`
+}
+
+type svgPath struct {
+	XMLName     xml.Name `xml:"path"`
+	D           string   `xml:"d,attr"`
+	Fill        string   `xml:"fill,attr"`
+	FillRule    string   `xml:"fill-rule,attr,omitempty"`
+	Stroke      string   `xml:"stroke,attr"`
+	StrokeWidth int      `xml:"stroke-width,attr"`
+	LineJoin    string   `xml:"stroke-linejoin,attr,omitempty"`
+}
+
+type svgText struct {
+	XMLName  xml.Name `xml:"text"`
+	X        int      `xml:"x,attr"`
+	Y        int      `xml:"y,attr"`
+	FontSize int      `xml:"font-size,attr"`
+	Font     string   `xml:"font-family,attr"`
+	Anchor   string   `xml:"text-anchor,attr"`
+	Baseline string   `xml:"dominant-baseline,attr"`
+	Text     string   `xml:",chardata"`
+}
+
+// RenderSVG draws the board as an SVG image. Each piece is a polygon filled
+// with the colour of its letter and outlined with a thick stroke, over a
+// thin grid of the board's cells.
+func RenderSVG(w io.Writer, b *Board, opts SVGOptions) error {
+	cell := opts.CellSize
+	if cell <= 0 {
+		cell = defaultCellSize
+	}
+	width, height := b.Width*cell, b.Height*cell
+	pad := outlineWidth
+
+	doc := svgDoc{
+		Xmlns:   "http://www.w3.org/2000/svg",
+		Width:   width + 2*pad,
+		Height:  height + 2*pad,
+		ViewBox: fmt.Sprintf("%d %d %d %d", -pad, -pad, width+2*pad, height+2*pad),
+	}
+	doc.Elements = append(doc.Elements,
+		svgRect{Width: width, Height: height, Fill: "#ffffff"},
+		svgPath{D: gridPath(b.Width, b.Height, cell), Fill: "none", Stroke: "#cccccc", StrokeWidth: 1},
+	)
+	for _, p := range b.Placements() {
+		doc.Elements = append(doc.Elements, svgPath{
+			D:           outlinePath(p.Cells, cell),
+			Fill:        hexColor(PieceColor(p.Index)),
+			FillRule:    "evenodd",
+			Stroke:      "#000000",
+			StrokeWidth: outlineWidth,
+			LineJoin:    "round",
+		})
+		if opts.Labels {
+			first := p.Cells[0]
+			doc.Elements = append(doc.Elements, svgText{
+				X:        first.X*cell + cell/2,
+				Y:        first.Y*cell + cell/2,
+				FontSize: cell / 2,
+				Font:     "sans-serif",
+				Anchor:   "middle",
+				Baseline: "central",
+				Text:     p.Letter,
+			})
+		}
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// PieceColor returns the colour used for the piece at index. Hues are
+// spread by the golden angle so neighbouring letters differ clearly.
+func PieceColor(index int) color.RGBA {
+	hue := math.Mod(float64(index)*137.508, 360)
+	return hslToRGB(hue, 0.65, 0.6)
+}
+
+// hslToRGB converts a hue in degrees and saturation and lightness in [0, 1].
+func hslToRGB(h, s, l float64) color.RGBA {
+	c := (1 - math.Abs(2*l-1)) * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := l - c/2
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g, b = c, x, 0
+	case h < 120:
+		r, g, b = x, c, 0
+	case h < 180:
+		r, g, b = 0, c, x
+	case h < 240:
+		r, g, b = 0, x, c
+	case h < 300:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+	scale := func(v float64) uint8 { return uint8(math.Round((v + m) * 255)) }
+	return color.RGBA{R: scale(r), G: scale(g), B: scale(b), A: 255}
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// gridPath returns the lines between the cells of a width x height board.
+func gridPath(width, height, cell int) string {
+	var d strings.Builder
+	for x := 0; x <= width; x++ {
+		fmt.Fprintf(&d, "M%d 0V%d", x*cell, height*cell)
+	}
+	for y := 0; y <= height; y++ {
+		fmt.Fprintf(&d, "M0 %dH%d", y*cell, width*cell)
+	}
+	return d.String()
+}
+
+// outlinePath returns the boundary of the given cells as closed loops. It
+// walks the cell edges that border no other cell of the piece, clockwise,
+// and merges straight runs into single segments.
+func outlinePath(cells []Point, cell int) string {
+	in := make(map[Point]bool, len(cells))
+	for _, c := range cells {
+		in[c] = true
+	}
+
+	// next maps each corner to the corners its boundary edges lead to.
+	next := make(map[Point][]Point)
+	var starts []Point
+	edge := func(from, to Point) {
+		if len(next[from]) == 0 {
+			starts = append(starts, from)
+		}
+		next[from] = append(next[from], to)
+	}
+	for _, c := range cells {
+		x, y := c.X, c.Y
+		if !in[Point{X: x, Y: y - 1}] {
+			edge(Point{X: x, Y: y}, Point{X: x + 1, Y: y})
+		}
+		if !in[Point{X: x + 1, Y: y}] {
+			edge(Point{X: x + 1, Y: y}, Point{X: x + 1, Y: y + 1})
+		}
+		if !in[Point{X: x, Y: y + 1}] {
+			edge(Point{X: x + 1, Y: y + 1}, Point{X: x, Y: y + 1})
+		}
+		if !in[Point{X: x - 1, Y: y}] {
+			edge(Point{X: x, Y: y + 1}, Point{X: x, Y: y})
+		}
+	}
+
+	var d strings.Builder
+	for _, start := range starts {
+		if len(next[start]) == 0 {
+			continue
+		}
+		loop := []Point{start}
+		for at := start; ; {
+			to := next[at][0]
+			next[at] = next[at][1:]
+			if to == start {
+				break
+			}
+			loop = append(loop, to)
+			at = to
+		}
+		for i, p := range corners(loop) {
+			cmd := "L"
+			if i == 0 {
+				cmd = "M"
+			}
+			fmt.Fprintf(&d, "%s%d %d", cmd, p.X*cell, p.Y*cell)
+		}
+		d.WriteString("Z")
+	}
+	return d.String()
+}
+
+// corners drops the points of a closed loop that lie on a straight line
+// between their neighbours.
+func corners(loop []Point) []Point {
+	n := len(loop)
+	var out []Point
+	for i, p := range loop {
+		prev, next := loop[(i+n-1)%n], loop[(i+1)%n]
+		if (prev.X == p.X && p.X == next.X) || (prev.Y == p.Y && p.Y == next.Y) {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
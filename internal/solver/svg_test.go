@@ -0,0 +1,103 @@
+package solver
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestOutlinePath(t *testing.T) {
+	tests := []struct {
+		name  string
+		cells []Point
+		want  string
+	}{
+		{
+			name:  "Square",
+			cells: []Point{{0, 0}, {1, 0}, {0, 1}, {1, 1}},
+			want:  "M0 0L20 0L20 20L0 20Z",
+		},
+		{
+			name:  "L",
+			cells: []Point{{0, 0}, {0, 1}, {0, 2}, {1, 2}},
+			want:  "M0 0L10 0L10 20L20 20L20 30L0 30Z",
+		},
+		{
+			name:  "Ring",
+			cells: []Point{{0, 0}, {1, 0}, {2, 0}, {0, 1}, {2, 1}, {0, 2}, {1, 2}, {2, 2}},
+			want:  "M0 0L30 0L30 30L0 30ZM20 10L10 10L10 20L20 20Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := outlinePath(tt.cells, 10); got != tt.want {
+				t.Errorf("outlinePath() = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderSVG(t *testing.T) {
+	board := NewRectBoard(3, 2)
+	board.Place(makeTetromino('A', []Point{{0, 0}, {1, 0}, {1, 1}}), 0, 0)
+	board.Place(makeTetromino('B', []Point{{0, 0}}), 2, 1)
+
+	var buf bytes.Buffer
+	if err := RenderSVG(&buf, board, SVGOptions{CellSize: 10, Labels: true}); err != nil {
+		t.Fatalf("RenderSVG() error = %v", err)
+	}
+	out := buf.String()
+
+	var doc struct {
+		Width  int `xml:"width,attr"`
+		Height int `xml:"height,attr"`
+		Paths  []struct {
+			D    string `xml:"d,attr"`
+			Fill string `xml:"fill,attr"`
+		} `xml:"path"`
+		Texts []string `xml:"text"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("RenderSVG() wrote invalid XML: %v\n%s", err, out)
+	}
+	if doc.Width != 36 || doc.Height != 26 {
+		t.Errorf("RenderSVG() size = %dx%d; want 36x26", doc.Width, doc.Height)
+	}
+	if len(doc.Paths) != 3 {
+		t.Fatalf("RenderSVG() wrote %d paths; want grid and 2 pieces\n%s", len(doc.Paths), out)
+	}
+	if got, want := doc.Paths[1].D, "M0 0L20 0L20 20L10 20L10 10L0 10Z"; got != want {
+		t.Errorf("RenderSVG() piece A = %q; want %q", got, want)
+	}
+	if got, want := doc.Paths[2].Fill, hexColor(PieceColor(1)); got != want {
+		t.Errorf("RenderSVG() piece B fill = %q; want %q", got, want)
+	}
+	if strings.Join(doc.Texts, "") != "AB" {
+		t.Errorf("RenderSVG() labels = %q; want [A B]", doc.Texts)
+	}
+
+	buf.Reset()
+	if err := RenderSVG(&buf, board, SVGOptions{}); err != nil {
+		t.Fatalf("RenderSVG() error = %v", err)
+	}
+	if strings.Contains(buf.String(), "<text") {
+		t.Error("RenderSVG() wrote labels without SVGOptions.Labels")
+	}
+}
+
+func TestPieceColor(t *testing.T) {
+	if got, want := PieceColor(0), (color.RGBA{R: 219, G: 87, B: 87, A: 255}); got != want {
+		t.Errorf("PieceColor(0) = %v; want %v", got, want)
+	}
+	seen := make(map[color.RGBA]bool)
+	for i := 0; i < 26; i++ {
+		c := PieceColor(i)
+		if seen[c] {
+			t.Errorf("PieceColor(%d) = %v repeats an earlier colour", i, c)
+		}
+		seen[c] = true
+	}
+}
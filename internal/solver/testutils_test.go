@@ -8,12 +8,12 @@ import (
 // createTestTetromino creates a tetromino from a 4x4 string representation.
 func createTestTetromino(shape []string, id int) (*Tetromino, error) {
 	if len(shape) != 4 {
-		return nil, newValidationError("test tetromino must have 4 rows")
+		return nil, NewValidationError(ErrInvalidFormat, "test tetromino must have 4 rows")
 	}
 	byteLines := make([][]byte, 4)
 	for i, line := range shape {
 		if len(line) != 4 {
-			return nil, newValidationError("test tetromino row must have 4 columns")
+			return nil, NewValidationError(ErrInvalidFormat, "test tetromino row must have 4 columns")
 		}
 		byteLines[i] = []byte(line)
 	}
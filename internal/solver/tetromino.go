@@ -1,128 +1,132 @@
 package solver
 
-import "fmt"
+import "math/bits"
 
 // Point represents a coordinate in a tetromino.
 type Point struct {
-	X, Y int
+	X int `json:"x"`
+	Y int `json:"y"`
 }
 
-// Tetromino represents a Tetris piece with 4 blocks.
-type Tetromino struct {
+// Polyomino represents a piece made of one or more connected blocks.
+type Polyomino struct {
 	Points []Point
 	Letter rune
 	Width  int
 	Height int
+
+	shape *pieceMask
 }
 
-// ValidateAndCreateTetromino creates a tetromino from a 4x4 block.
-func ValidateAndCreateTetromino(block [][]byte, blockNumber int) (*Tetromino, error) {
-	if len(block) != 4 {
-		return nil, fmt.Errorf("ERROR")
-	}
+// Tetromino represents a Tetris piece with 4 blocks.
+type Tetromino = Polyomino
+
+// pieceMask holds one bit mask per row of a piece, with bit x set when the
+// piece covers column x of that row.
+type pieceMask struct {
+	rows  []uint64
+	width int
+}
 
-	var (
-		hashCount  int
-		points     [4]Point
-		minX, maxX = 3, 0
-		minY, maxY = 3, 0
-		pointIdx   int
-	)
-
-	for y, line := range block {
-		if len(line) != 4 {
-			return nil, fmt.Errorf("ERROR")
+// newPieceMask builds the row masks for points normalized to the top-left.
+func newPieceMask(points []Point) *pieceMask {
+	m := &pieceMask{}
+	for _, p := range points {
+		for len(m.rows) <= p.Y {
+			m.rows = append(m.rows, 0)
 		}
-		for x, char := range line {
-			if char == '#' {
-				if hashCount == 4 {
-					return nil, fmt.Errorf("ERROR")
-				}
-				points[pointIdx] = Point{X: x, Y: y}
-				pointIdx++
-				hashCount++
-				if x < minX {
-					minX = x
-				}
-				if x > maxX {
-					maxX = x
-				}
-				if y < minY {
-					minY = y
-				}
-				if y > maxY {
-					maxY = y
-				}
-			} else if char != '.' {
-				return nil, fmt.Errorf("ERROR")
-			}
+		m.rows[p.Y] |= 1 << uint(p.X)
+		if p.X+1 > m.width {
+			m.width = p.X + 1
 		}
 	}
+	return m
+}
 
-	if hashCount != 4 {
-		return nil, fmt.Errorf("ERROR")
+// mask returns the piece's row masks, building them on first use.
+func (t *Tetromino) mask() *pieceMask {
+	if t.shape == nil {
+		t.shape = newPieceMask(t.Points)
 	}
+	return t.shape
+}
 
-	// Normalize points to top-left
-	for i := range points {
-		points[i].X -= minX
-		points[i].Y -= minY
-	}
+// lead returns the column of the leftmost cell in the tetromino's top row.
+func (t *Tetromino) lead() int {
+	return bits.TrailingZeros64(t.mask().rows[0])
+}
 
-	if !isValidTetromino(points) {
-		return nil, fmt.Errorf("ERROR")
+// Rotate returns a copy of the tetromino turned 90 degrees clockwise.
+func (t *Tetromino) Rotate() *Tetromino {
+	points := make([]Point, len(t.Points))
+	for i, p := range t.Points {
+		points[i] = Point{X: -p.Y, Y: p.X}
 	}
+	return newOrientation(points, t.Letter)
+}
 
-	return &Tetromino{
-		Points: points[:],
-		Letter: 'A' + rune(blockNumber),
-		Width:  maxX - minX + 1,
-		Height: maxY - minY + 1,
-	}, nil
+// Reflect returns a mirror image of the tetromino, flipped left to right.
+func (t *Tetromino) Reflect() *Tetromino {
+	points := make([]Point, len(t.Points))
+	for i, p := range t.Points {
+		points[i] = Point{X: -p.X, Y: p.Y}
+	}
+	return newOrientation(points, t.Letter)
 }
 
-// isValidTetromino checks if the points form a valid, connected tetromino.
-func isValidTetromino(points [4]Point) bool {
-	// Check for duplicates
-	for i := 0; i < 4; i++ {
-		for j := i + 1; j < 4; j++ {
-			if points[i].X == points[j].X && points[i].Y == points[j].Y {
-				return false
-			}
+// Orientations returns the distinct ways the tetromino can be turned, and
+// with mirror also flipped, starting with the tetromino itself. An O piece
+// has one orientation, an I piece two and a T piece four.
+func (t *Tetromino) Orientations(mirror bool) []*Tetromino {
+	candidates := []*Tetromino{t}
+	for i := 0; i < 3; i++ {
+		candidates = append(candidates, candidates[i].Rotate())
+	}
+	if mirror {
+		candidates = append(candidates, t.Reflect())
+		for i := 4; i < 7; i++ {
+			candidates = append(candidates, candidates[i].Rotate())
 		}
 	}
 
-	// Build adjacency list
-	adj := make([][]int, 4)
-	for i := 0; i < 4; i++ {
-		for j := i + 1; j < 4; j++ {
-			dx := points[i].X - points[j].X
-			dy := points[i].Y - points[j].Y
-			if (dx == 1 && dy == 0) || (dx == -1 && dy == 0) ||
-				(dx == 0 && dy == 1) || (dx == 0 && dy == -1) {
-				adj[i] = append(adj[i], j)
-				adj[j] = append(adj[j], i)
+	var orientations []*Tetromino
+	for _, c := range candidates {
+		duplicate := false
+		for _, o := range orientations {
+			if areTetrominosEqual(c, o) {
+				duplicate = true
+				break
 			}
 		}
+		if !duplicate {
+			orientations = append(orientations, c)
+		}
 	}
+	return orientations
+}
 
-	// BFS to check connectivity
-	visited := make([]bool, 4)
-	queue := []int{0}
-	visited[0] = true
-	count := 1
-
-	for len(queue) > 0 {
-		current := queue[0]
-		queue = queue[1:]
-		for _, neighbor := range adj[current] {
-			if !visited[neighbor] {
-				visited[neighbor] = true
-				queue = append(queue, neighbor)
-				count++
-			}
-		}
+// newOrientation builds a tetromino from points in any position, moving
+// them to the top-left corner.
+func newOrientation(points []Point, letter rune) *Tetromino {
+	t := &Tetromino{Points: points, Letter: letter}
+	t.Points = normalizeTetromino(t)
+	for _, p := range t.Points {
+		t.Width = max(t.Width, p.X+1)
+		t.Height = max(t.Height, p.Y+1)
+	}
+	t.shape = newPieceMask(t.Points)
+	return t
+}
+
+// ValidateAndCreateTetromino creates a tetromino from a 4x4 block.
+func ValidateAndCreateTetromino(block [][]byte, blockNumber int) (*Tetromino, error) {
+	if len(block) != 4 {
+		return nil, newParseError(ReasonIncompleteBlock, blockNumber, len(block), 0)
 	}
+	return createPolyomino(block, blockNumber, 4)
+}
 
-	return count == 4
+// isValidTetromino checks if the points form a valid, connected tetromino.
+func isValidTetromino(points [4]Point) bool {
+	return isConnected(points[:])
 }
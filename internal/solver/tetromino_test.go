@@ -34,7 +34,7 @@ func TestValidateAndCreateTetromino(t *testing.T) {
 				[]byte("##.."),
 			},
 			wantErr:    true,
-			wantErrMsg: "ERROR",
+			wantErrMsg: "3:1: block 1: incomplete block",
 		},
 		{
 			name: "InvalidColumnCount",
@@ -45,7 +45,7 @@ func TestValidateAndCreateTetromino(t *testing.T) {
 				[]byte("...."),
 			},
 			wantErr:    true,
-			wantErrMsg: "ERROR",
+			wantErrMsg: "1:4: block 1: wrong row width",
 		},
 		{
 			name: "TooManyBlocks",
@@ -56,7 +56,7 @@ func TestValidateAndCreateTetromino(t *testing.T) {
 				[]byte("...."),
 			},
 			wantErr:    true,
-			wantErrMsg: "ERROR",
+			wantErrMsg: "2:2: block 1: too many '#'",
 		},
 		{
 			name: "InvalidCharacter",
@@ -67,18 +67,18 @@ func TestValidateAndCreateTetromino(t *testing.T) {
 				[]byte("...."),
 			},
 			wantErr:    true,
-			wantErrMsg: "ERROR",
+			wantErrMsg: "1:2: block 1: bad character",
 		},
 		{
 			name: "DisconnectedTetromino",
 			block: [][]byte{
 				[]byte("#.##"),
-				[]byte("...."),
+				[]byte("#..."),
 				[]byte("...."),
 				[]byte("...."),
 			},
 			wantErr:    true,
-			wantErrMsg: "ERROR",
+			wantErrMsg: "1:3: block 1: disconnected piece",
 		},
 	}
 
@@ -139,4 +139,104 @@ func TestIsValidTetromino(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestNewPieceMask(t *testing.T) {
+	tests := []struct {
+		name      string
+		points    []Point
+		wantRows  []uint64
+		wantWidth int
+	}{
+		{
+			name:      "Square",
+			points:    []Point{{0, 0}, {1, 0}, {0, 1}, {1, 1}},
+			wantRows:  []uint64{0b11, 0b11},
+			wantWidth: 2,
+		},
+		{
+			name:      "T",
+			points:    []Point{{0, 0}, {1, 0}, {2, 0}, {1, 1}},
+			wantRows:  []uint64{0b111, 0b010},
+			wantWidth: 3,
+		},
+		{
+			name:      "Vertical",
+			points:    []Point{{0, 0}, {0, 1}, {0, 2}, {0, 3}},
+			wantRows:  []uint64{1, 1, 1, 1},
+			wantWidth: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newPieceMask(tt.points)
+			if !reflect.DeepEqual(got.rows, tt.wantRows) {
+				t.Errorf("newPieceMask() rows = %b; want %b", got.rows, tt.wantRows)
+			}
+			if got.width != tt.wantWidth {
+				t.Errorf("newPieceMask() width = %d; want %d", got.width, tt.wantWidth)
+			}
+		})
+	}
+}
+
+func TestRotateAndReflect(t *testing.T) {
+	l, err := createTestTetromino([]string{
+		"#...",
+		"#...",
+		"##..",
+		"....",
+	}, 0)
+	if err != nil {
+		t.Fatalf("ERROR")
+	}
+
+	rotated := l.Rotate()
+	wantRotated := []Point{{0, 0}, {1, 0}, {2, 0}, {0, 1}}
+	if !reflect.DeepEqual(rotated.Points, wantRotated) {
+		t.Errorf("Rotate() Points = %v; want %v", rotated.Points, wantRotated)
+	}
+	if rotated.Width != 3 || rotated.Height != 2 || rotated.Letter != l.Letter {
+		t.Errorf("Rotate() = %dx%d %q; want 3x2 %q", rotated.Width, rotated.Height, rotated.Letter, l.Letter)
+	}
+
+	reflected := l.Reflect()
+	wantReflected := []Point{{1, 0}, {1, 1}, {0, 2}, {1, 2}}
+	if !reflect.DeepEqual(reflected.Points, wantReflected) {
+		t.Errorf("Reflect() Points = %v; want %v", reflected.Points, wantReflected)
+	}
+}
+
+func TestOrientations(t *testing.T) {
+	tests := []struct {
+		name       string
+		shape      []string
+		wantTurns  int
+		wantMirror int
+	}{
+		{"O", []string{"##..", "##..", "....", "...."}, 1, 1},
+		{"I", []string{"####", "....", "....", "...."}, 2, 2},
+		{"S", []string{".##.", "##..", "....", "...."}, 2, 4},
+		{"T", []string{"###.", ".#..", "....", "...."}, 4, 4},
+		{"L", []string{"#...", "#...", "##..", "...."}, 4, 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tetromino, err := createTestTetromino(tt.shape, 0)
+			if err != nil {
+				t.Fatalf("ERROR")
+			}
+			turns := tetromino.Orientations(false)
+			if len(turns) != tt.wantTurns {
+				t.Errorf("Orientations(false) len = %d; want %d", len(turns), tt.wantTurns)
+			}
+			if turns[0] != tetromino {
+				t.Error("Orientations(false)[0] is not the tetromino itself")
+			}
+			if got := len(tetromino.Orientations(true)); got != tt.wantMirror {
+				t.Errorf("Orientations(true) len = %d; want %d", got, tt.wantMirror)
+			}
+		})
+	}
+}
@@ -1,148 +1,250 @@
 package solver
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 )
 
-const (
-	tetrisDir = "testfiles"
-	minLines  = 4
-)
-
 // Validate validates a Tetris input file and returns the solved board.
-func Validate(filename string) (string, error) {
-	// Clean the input path
-	cleanFilename := filepath.Clean(filename)
+func Validate(filename string, opts ...Option) (string, error) {
+	return ValidateContext(context.Background(), filename, opts...)
+}
 
-	// Get absolute path of the tetris directory
-	absTetrisDir, err := filepath.Abs(tetrisDir)
+// ValidateContext is like Validate but stops solving once ctx is done.
+func ValidateContext(ctx context.Context, filename string, opts ...Option) (string, error) {
+	tetrominos, err := ReadTetrominos(filename, opts...)
 	if err != nil {
-		return "", fmt.Errorf("invalid tetris directory: %v", err)
+		return "", err
 	}
+	return SolveTetrominosContext(ctx, tetrominos, opts...)
+}
 
-	// Get absolute path of the requested file
-	absFilePath, err := filepath.Abs(cleanFilename)
+// SolveReader reads a puzzle from r and returns the solved board.
+func SolveReader(r io.Reader, opts ...Option) (string, error) {
+	return SolveReaderContext(context.Background(), r, opts...)
+}
+
+// SolveReaderContext is like SolveReader but stops solving once ctx is done.
+func SolveReaderContext(ctx context.Context, r io.Reader, opts ...Option) (string, error) {
+	tetrominos, err := Parse(r, opts...)
 	if err != nil {
-		return "", fmt.Errorf("invalid file path: %v", err)
+		return "", err
 	}
+	return SolveTetrominosContext(ctx, tetrominos, opts...)
+}
 
-	// If file isn't already in the tetris directory, join them
-	if !strings.HasPrefix(absFilePath, absTetrisDir+string(filepath.Separator)) {
-		absFilePath = filepath.Join(absTetrisDir, cleanFilename)
+// Parse reads a puzzle from r and returns its tetrominos without solving
+// them.
+func Parse(r io.Reader, opts ...Option) ([]*Tetromino, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, NewValidationError(ErrUnreadable, "error reading input")
 	}
+	return parseTetrominos(string(content), newOptions(opts))
+}
 
-	// Prevent directory traversal
-	if !strings.HasPrefix(absFilePath, absTetrisDir+string(filepath.Separator)) {
-		return "", NewValidationError("invalid file path: attempted directory traversal")
+// ReadTetrominos validates a Tetris input file and returns its tetrominos
+// without solving them. The file may live anywhere unless WithSandbox
+// confines it to a directory.
+func ReadTetrominos(filename string, opts ...Option) ([]*Tetromino, error) {
+	o := newOptions(opts)
+	path := filepath.Clean(filename)
+	if o.sandbox != "" {
+		var err error
+		if path, err = sandboxPath(o.sandbox, path); err != nil {
+			return nil, err
+		}
+		if err := validateStructure(path); err != nil {
+			return nil, err
+		}
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, NewValidationError(ErrUnreadable, "error reading file")
 	}
+	tetrominos, err := parseTetrominos(string(content), o)
+	var perr *ParseError
+	if errors.As(err, &perr) {
+		perr.File = filename
+	}
+	return tetrominos, err
+}
 
-	if err := validateStructure(absFilePath); err != nil {
-		return "", err
+// sandboxPath resolves filename inside dir and rejects paths that escape
+// it.
+func sandboxPath(dir, filename string) (string, error) {
+	// Get absolute path of the sandbox directory
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", NewValidationError(ErrPathRejected, fmt.Sprintf("invalid sandbox directory: %v", err))
+	}
+
+	// Get absolute path of the requested file
+	absFilePath, err := filepath.Abs(filename)
+	if err != nil {
+		return "", NewValidationError(ErrPathRejected, fmt.Sprintf("invalid file path: %v", err))
+	}
+
+	// If file isn't already in the sandbox directory, join them
+	if !strings.HasPrefix(absFilePath, absDir+string(filepath.Separator)) {
+		absFilePath = filepath.Join(absDir, filename)
+	}
+
+	// Prevent directory traversal
+	if !strings.HasPrefix(absFilePath, absDir+string(filepath.Separator)) {
+		return "", NewValidationError(ErrPathRejected, "invalid file path: attempted directory traversal")
 	}
-	return validateAndSolveContent(absFilePath)
+	return absFilePath, nil
 }
 
 // validateStructure checks the file's structure (extension and existence).
 func validateStructure(fullPath string) error {
 	if filepath.Ext(fullPath) != ".txt" {
-		return NewValidationError("file must have .txt extension")
+		return NewValidationError(ErrPathRejected, "file must have .txt extension")
 	}
 
 	if _, err := os.Stat(fullPath); err != nil {
 		if os.IsNotExist(err) {
-			return NewValidationError("file does not exist in directory")
+			return NewValidationError(ErrUnreadable, "file does not exist in directory")
 		}
-		return NewValidationError("file access error")
+		return NewValidationError(ErrUnreadable, "file access error")
 	}
 	return nil
 }
 
-// validateAndSolveContent reads and processes the file content.
-func validateAndSolveContent(fullPath string) (string, error) {
-	content, err := os.ReadFile(fullPath)
+// validateAndSolve validates the content and solves the tetromino puzzle.
+func validateAndSolve(ctx context.Context, content string, opts ...Option) (string, error) {
+	tetrominos, err := parseTetrominos(content, newOptions(opts))
 	if err != nil {
-		return "", NewValidationError("error reading file")
+		return "", err
 	}
-	return validateAndSolve(string(content))
+	return SolveTetrominosContext(ctx, tetrominos, opts...)
 }
 
-// validateAndSolve validates the content and solves the tetromino puzzle.
-func validateAndSolve(content string) (string, error) {
-	if len(content) < 16 {
-		return "", NewValidationError("ERROR")
+// parseTetrominos validates the content and builds its tetrominos. Blocks
+// are 4x4, or KxK with K taken from the first line when polyominoes are
+// enabled. Errors are *ParseError values positioned in content.
+func parseTetrominos(content string, o *options) ([]*Tetromino, error) {
+	lines := strings.Split(content, "\n")
+	if strings.TrimSpace(content) == "" {
+		return nil, newParseError(ReasonEmpty, 0, 0, 0)
+	}
+	size := 4
+	if o.polyominoes {
+		size = len(strings.TrimSpace(lines[0]))
+		if size == 0 {
+			return nil, newParseError(ReasonIncompleteBlock, 0, 0, 0)
+		}
 	}
 
-	lines := strings.Split(content, "\n")
 	var (
 		lineCount    int
-		blockLines   [4]string
+		blockLines   = make([]string, size)
+		blockIndent  = make([]int, size)
 		blockIndex   int
 		hasContent   bool
 		blockCounter int
 		tetrominos   []*Tetromino
 	)
+	create := validateAndCreateTetrominoStr
+	if o.polyominoes {
+		create = validateAndCreatePolyominoStr
+	}
+	// build creates the current block, moving any error from block
+	// coordinates to content coordinates.
+	build := func(rows, first int) error {
+		tetromino, err := create(blockLines[:rows], blockCounter)
+		var perr *ParseError
+		if errors.As(err, &perr) {
+			if perr.Line <= rows {
+				perr.Column += blockIndent[perr.Line-1]
+			}
+			perr.Line += first
+			return perr
+		}
+		if err != nil {
+			return err
+		}
+		tetrominos = append(tetrominos, tetromino)
+		blockCounter++
+		return nil
+	}
 
 	for _, line := range lines {
 		lineCount++
 		trimmed := strings.TrimSpace(line)
+		indent := len(line) - len(strings.TrimLeft(line, " \t\r"))
 
-		for _, char := range line {
+		for col, char := range line {
 			if char != '#' && char != '.' && char != '\n' && char != '\r' && char != ' ' && char != '\t' {
-				return "", NewValidationError("ERROR")
+				return nil, newParseError(ReasonBadCharacter, blockCounter, lineCount-1, col)
 			}
 		}
 
-		if lineCount%5 == 0 {
+		if lineCount%(size+1) == 0 {
 			if len(trimmed) > 0 {
-				return "", NewValidationError("ERROR")
+				return nil, newParseError(ReasonMissingSeparator, blockCounter, lineCount-1, indent)
 			}
-			if blockIndex == 4 {
-				tetromino, err := validateAndCreateTetrominoStr(blockLines[:], blockCounter)
-				if err != nil {
-					return "", err
+			if blockIndex == size {
+				if err := build(size, lineCount-1-size); err != nil {
+					return nil, err
 				}
-				tetrominos = append(tetrominos, tetromino)
-				blockCounter++
 			}
 			blockIndex = 0
 			continue
 		}
 
-		if len(trimmed) == 0 || len(trimmed) != 4 {
-			return "", NewValidationError("ERROR")
+		if len(trimmed) == 0 {
+			return nil, newParseError(ReasonIncompleteBlock, blockCounter, lineCount-1, 0)
+		}
+		if len(trimmed) != size {
+			return nil, newParseError(ReasonRowWidth, blockCounter, lineCount-1, indent+min(len(trimmed), size))
 		}
 
-		if blockIndex >= 4 {
-			return "", NewValidationError("ERROR")
+		if blockIndex >= size {
+			return nil, newParseError(ReasonMissingSeparator, blockCounter, lineCount-1, indent)
 		}
 		blockLines[blockIndex] = trimmed
+		blockIndent[blockIndex] = indent
 		blockIndex++
 		hasContent = true
 	}
 
 	if blockIndex > 0 {
-		tetromino, err := validateAndCreateTetrominoStr(blockLines[:blockIndex], blockCounter)
-		if err != nil {
-			return "", err
+		if blockIndex < size {
+			return nil, newParseError(ReasonIncompleteBlock, blockCounter, lineCount, 0)
+		}
+		if err := build(blockIndex, lineCount-blockIndex); err != nil {
+			return nil, err
 		}
-		tetrominos = append(tetrominos, tetromino)
 	}
 
-	if !hasContent || lineCount < minLines {
-		return "", NewValidationError("ERROR")
+	if !hasContent || lineCount < size {
+		return nil, newParseError(ReasonEmpty, 0, lineCount-1, 0)
 	}
 
-	return SolveTetrominos(tetrominos)
+	return tetrominos, nil
 }
 
 // validateAndCreateTetrominoStr converts string lines to a tetromino.
 func validateAndCreateTetrominoStr(lines []string, id int) (*Tetromino, error) {
+	return ValidateAndCreateTetromino(toByteLines(lines), id)
+}
+
+// validateAndCreatePolyominoStr converts string lines to a polyomino.
+func validateAndCreatePolyominoStr(lines []string, id int) (*Polyomino, error) {
+	return ValidateAndCreatePolyomino(toByteLines(lines), id)
+}
+
+func toByteLines(lines []string) [][]byte {
 	byteLines := make([][]byte, len(lines))
 	for i, line := range lines {
 		byteLines[i] = []byte(line)
 	}
-	return ValidateAndCreateTetromino(byteLines, id)
+	return byteLines
 }
\ No newline at end of file
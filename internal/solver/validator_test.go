@@ -1,10 +1,13 @@
 package solver
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
-	
+	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 func TestValidate(t *testing.T) {
@@ -22,6 +25,7 @@ func TestValidate(t *testing.T) {
 	tests := []struct {
 		name        string
 		filename    string
+		opts        []Option
 		wantErr     bool
 		wantErrMsg  string
 		wantBoard   string
@@ -29,26 +33,46 @@ func TestValidate(t *testing.T) {
 		{
 			name:      "ValidFile",
 			filename:  "test.txt",
+			opts:      []Option{WithSandbox("testfiles")},
 			wantBoard: "AA\nAA\n",
 		},
 		{
 			name:       "InvalidExtension",
 			filename:   "test.dat",
+			opts:       []Option{WithSandbox("testfiles")},
 			wantErr:    true,
 			wantErrMsg: "file must have .txt extension",
 		},
 		{
 			name:       "NonExistentFile",
 			filename:   "nonexistent.txt",
+			opts:       []Option{WithSandbox("testfiles")},
 			wantErr:    true,
 			wantErrMsg: "file does not exist in directory",
 		},
 		{
 			name:       "DirectoryTraversal",
 			filename:   "../test.txt",
+			opts:       []Option{WithSandbox("testfiles")},
 			wantErr:    true,
 			wantErrMsg: "invalid file path: attempted directory traversal",
 		},
+		{
+			name:      "RelativePathWithoutSandbox",
+			filename:  "testfiles/test.txt",
+			wantBoard: "AA\nAA\n",
+		},
+		{
+			name:      "AbsolutePathWithoutSandbox",
+			filename:  testFilePath,
+			wantBoard: "AA\nAA\n",
+		},
+		{
+			name:       "MissingFileWithoutSandbox",
+			filename:   "test.txt",
+			wantErr:    true,
+			wantErrMsg: "error reading file",
+		},
 	}
 
 	for _, tt := range tests {
@@ -58,7 +82,7 @@ func TestValidate(t *testing.T) {
 			os.Chdir(tmpDir)
 			defer os.Chdir(origDir)
 
-			got, err := Validate(tt.filename)
+			got, err := Validate(tt.filename, tt.opts...)
 			if tt.wantErr {
 				if err == nil {
 					t.Errorf("Validate() error = nil; want %q", tt.wantErrMsg)
@@ -77,6 +101,23 @@ func TestValidate(t *testing.T) {
 	}
 }
 
+func TestSolveReader(t *testing.T) {
+	got, err := SolveReader(strings.NewReader("##..\n##..\n....\n...."))
+	if err != nil {
+		t.Fatalf("SolveReader() error = %v; want nil", err)
+	}
+	if !compareBoards(got, "AA\nAA") {
+		t.Errorf("SolveReader() = %q; want %q", got, "AA\nAA")
+	}
+
+	if _, err := Parse(strings.NewReader("#...\n#...\n")); err == nil {
+		t.Error("Parse() error = nil; want error for a truncated block")
+	}
+	if _, err := Parse(iotest.ErrReader(errors.New("boom"))); err == nil {
+		t.Error("Parse() error = nil; want error from the reader")
+	}
+}
+
 func TestValidateAndSolve(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -94,31 +135,31 @@ func TestValidateAndSolve(t *testing.T) {
 			name:       "TooShort",
 			content:    "##..\n##..",
 			wantErr:    true,
-			wantErrMsg: "ERROR",
+			wantErrMsg: "3:1: block 1: incomplete block",
 		},
 		{
 			name:       "InvalidCharacter",
 			content:    "#X..\n##..\n....\n....",
 			wantErr:    true,
-			wantErrMsg: "ERROR",
+			wantErrMsg: "1:2: block 1: bad character",
 		},
 		{
 			name:       "InvalidLineLength",
 			content:    "###\n##..\n....\n....",
 			wantErr:    true,
-			wantErrMsg: "ERROR",
+			wantErrMsg: "1:4: block 1: wrong row width",
 		},
 		{
 			name:       "InvalidSeparator",
 			content:    "##..\n##..\n....\n....\n##..",
 			wantErr:    true,
-			wantErrMsg: "ERROR",
+			wantErrMsg: "5:1: block 1: missing separator",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := validateAndSolve(tt.content)
+			got, err := validateAndSolve(context.Background(), tt.content)
 			if tt.wantErr {
 				if err == nil {
 					t.Errorf("validateAndSolve() error = nil; want %q", tt.wantErrMsg)
@@ -135,4 +176,169 @@ func TestValidateAndSolve(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestValidateAndSolvePolyominoes(t *testing.T) {
+	pentominoes := ".....\n.##..\n.##..\n.#...\n.....\n\n#....\n#....\n#....\n#....\n#....\n"
+	mixed := "##.\n...\n...\n\n.#.\n##.\n...\n"
+
+	tests := []struct {
+		name      string
+		content   string
+		opts      []Option
+		wantErr   bool
+		wantBoard string
+	}{
+		{
+			name:      "Pentominoes",
+			content:   pentominoes,
+			opts:      []Option{WithPolyominoes()},
+			wantBoard: "AAB..\nAAB..\nA.B..\n..B..\n..B..",
+		},
+		{
+			name:      "MixedSizes",
+			content:   mixed,
+			opts:      []Option{WithPolyominoes()},
+			wantBoard: ".B.\nBB.\nAA.",
+		},
+		{
+			name:    "PentominoesWithoutOption",
+			content: pentominoes,
+			wantErr: true,
+		},
+		{
+			name:    "TetrominoModeRejectsThreeBlocks",
+			content: "###.\n....\n....\n....",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := validateAndSolve(context.Background(), tt.content, tt.opts...)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("validateAndSolve() = %q; want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("validateAndSolve() error = %v; want nil", err)
+			}
+			if !compareBoards(got, tt.wantBoard) {
+				t.Errorf("validateAndSolve() = %q; want %q", got, tt.wantBoard)
+			}
+		})
+	}
+}
+
+func TestParseErrorPosition(t *testing.T) {
+	square := "##..\n##..\n....\n....\n"
+
+	tests := []struct {
+		name    string
+		content string
+		opts    []Option
+		want    ParseError
+	}{
+		{
+			name:    "Empty",
+			content: "\n\n",
+			want:    ParseError{Line: 1, Column: 1, Block: 0, Reason: ReasonEmpty},
+		},
+		{
+			name:    "BadCharacterInSecondBlock",
+			content: square + "\n#...\n#.x.\n##..\n....",
+			want:    ParseError{Line: 7, Column: 3, Block: 1, Reason: ReasonBadCharacter},
+		},
+		{
+			name:    "RowWidth",
+			content: square + "\n#...\n#.....\n##..\n....",
+			want:    ParseError{Line: 7, Column: 5, Block: 1, Reason: ReasonRowWidth},
+		},
+		{
+			name:    "TooManyCellsWithIndent",
+			content: square + "\n  #...\n  #...\n  ###.\n  ....",
+			want:    ParseError{Line: 8, Column: 5, Block: 1, Reason: ReasonTooManyCells},
+		},
+		{
+			name:    "TooFewCells",
+			content: square + "\n#...\n#...\n#...\n....",
+			want:    ParseError{Line: 9, Column: 4, Block: 1, Reason: ReasonTooFewCells},
+		},
+		{
+			name:    "Disconnected",
+			content: "##..\n....\n..##\n....",
+			want:    ParseError{Line: 3, Column: 3, Block: 0, Reason: ReasonDisconnected},
+		},
+		{
+			name:    "MissingSeparator",
+			content: square + "##..\n##..\n....\n....",
+			want:    ParseError{Line: 5, Column: 1, Block: 0, Reason: ReasonMissingSeparator},
+		},
+		{
+			name:    "IncompleteBlock",
+			content: square + "\n#...\n#...\n\n",
+			want:    ParseError{Line: 8, Column: 1, Block: 1, Reason: ReasonIncompleteBlock},
+		},
+		{
+			name:    "DisconnectedPolyomino",
+			content: "#..\n...\n..#",
+			opts:    []Option{WithPolyominoes()},
+			want:    ParseError{Line: 3, Column: 3, Block: 0, Reason: ReasonDisconnected},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.content), tt.opts...)
+			var got *ParseError
+			if !errors.As(err, &got) {
+				t.Fatalf("Parse() error = %v; want *ParseError", err)
+			}
+			if *got != tt.want {
+				t.Errorf("Parse() error = %+v; want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadTetrominosParseErrorFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.txt")
+	if err := os.WriteFile(path, []byte("#x..\n##..\n....\n...."), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	_, err := ReadTetrominos(path)
+	if want := path + ":1:2: block 1: bad character"; err == nil || err.Error() != want {
+		t.Errorf("ReadTetrominos() error = %v; want %q", err, want)
+	}
+}
+
+func TestReadTetrominosErrorKinds(t *testing.T) {
+	dir := t.TempDir()
+	bad := filepath.Join(dir, "bad.txt")
+	if err := os.WriteFile(bad, []byte("#...\n#...\n#...\n...."), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		filename string
+		opts     []Option
+		want     error
+	}{
+		{"Missing", filepath.Join(dir, "missing.txt"), nil, ErrUnreadable},
+		{"Traversal", "../bad.txt", []Option{WithSandbox(dir)}, ErrPathRejected},
+		{"Extension", "bad.dat", []Option{WithSandbox(dir)}, ErrPathRejected},
+		{"InvalidPiece", bad, nil, ErrInvalidPiece},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadTetrominos(tt.filename, tt.opts...)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("ReadTetrominos() error = %v; want %v", err, tt.want)
+			}
+		})
+	}
+}
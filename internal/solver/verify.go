@@ -0,0 +1,150 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Violation is one way a solution breaks the rules. Line and Column are
+// 1-based and zero when the violation concerns the board as a whole;
+// Letter is zero when it concerns no single piece.
+type Violation struct {
+	Line, Column int
+	Letter       rune
+	Message      string
+}
+
+func (v Violation) String() string {
+	if v.Line == 0 {
+		return v.Message
+	}
+	return fmt.Sprintf("%d:%d: %s", v.Line, v.Column, v.Message)
+}
+
+// VerificationError lists every violation Verify found. It matches
+// ErrInvalidSolution.
+type VerificationError struct {
+	Violations []Violation
+}
+
+func (e *VerificationError) Error() string {
+	msg := e.Violations[0].String()
+	if n := len(e.Violations) - 1; n > 0 {
+		msg += fmt.Sprintf(" (and %d more)", n)
+	}
+	return msg
+}
+
+// Is reports whether target is ErrInvalidSolution.
+func (e *VerificationError) Is(target error) bool {
+	return target == ErrInvalidSolution
+}
+
+// Verify checks that solution, in the format SolveTetrominos prints, packs
+// pieces: every piece appears exactly once under its own letter, in its
+// given orientation, and no letter marks anything else. The board must be
+// square unless WithWidth or WithRectangle allow otherwise, and WithRotations
+// accepts turned pieces. With WithMinimalSize the board must also be as
+// small as the solver can make it. Violations are reported together in a
+// *VerificationError.
+func Verify(pieces []*Tetromino, solution string, opts ...Option) error {
+	return VerifyContext(context.Background(), pieces, solution, opts...)
+}
+
+// VerifyContext is like Verify but stops the WithMinimalSize search once
+// ctx is done.
+func VerifyContext(ctx context.Context, pieces []*Tetromino, solution string, opts ...Option) error {
+	o := newOptions(opts)
+	var violations []Violation
+	report := func(line, column int, letter rune, format string, args ...any) {
+		violations = append(violations, Violation{Line: line, Column: column, Letter: letter, Message: fmt.Sprintf(format, args...)})
+	}
+
+	rows := strings.Split(strings.TrimRight(strings.ReplaceAll(solution, "\r\n", "\n"), "\n"), "\n")
+	height, width := len(rows), len(rows[0])
+	cells := make(map[rune][]Point)
+	var order []rune
+	for y, row := range rows {
+		if len(row) != width {
+			report(y+1, min(len(row), width)+1, 0, "row is %d wide; want %d", len(row), width)
+			continue
+		}
+		for x, r := range row {
+			switch {
+			case r == '.':
+			case r >= 'A' && r < 'A'+rune(len(pieces)):
+				if len(cells[r]) == 0 {
+					order = append(order, r)
+				}
+				cells[r] = append(cells[r], Point{X: x, Y: y})
+			default:
+				report(y+1, x+1, r, "%q is not a piece of the puzzle", r)
+			}
+		}
+	}
+	if len(violations) > 0 {
+		return &VerificationError{Violations: violations}
+	}
+
+	switch {
+	case o.width > 0 && width != o.width:
+		report(0, 0, 0, "board is %d wide; want %d", width, o.width)
+	case o.width == 0 && !o.rectangle && width != height:
+		report(0, 0, 0, "board is %dx%d; want a square", width, height)
+	}
+
+	for i, t := range pieces {
+		letter := 'A' + rune(i)
+		found := cells[letter]
+		if len(found) == 0 {
+			report(0, 0, letter, "piece %c is missing", letter)
+			continue
+		}
+		if i := unreachable(found); i >= 0 {
+			p := found[i]
+			report(p.Y+1, p.X+1, letter, "letter %c marks more than one piece", letter)
+			continue
+		}
+		if !matchesPiece(t, found, o) {
+			p := found[0]
+			report(p.Y+1, p.X+1, letter, "piece %c does not have its input shape", letter)
+		}
+	}
+
+	if len(violations) == 0 && o.minimal {
+		best, err := SolveBoardContext(ctx, clonePieces(pieces), opts...)
+		if err != nil {
+			return err
+		}
+		if width*height > best.Width*best.Height {
+			report(0, 0, 0, "board is %dx%d; the pieces fit in %dx%d", width, height, best.Width, best.Height)
+		}
+	}
+
+	if len(violations) > 0 {
+		return &VerificationError{Violations: violations}
+	}
+	return nil
+}
+
+// matchesPiece reports whether cells form t in an orientation o allows.
+func matchesPiece(t *Tetromino, cells []Point, o *options) bool {
+	found := newOrientation(cells, t.Letter)
+	for _, want := range pieceOrientations([]*Tetromino{t}, o)[0] {
+		if areTetrominosEqual(found, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// clonePieces copies pieces so solving them does not relabel the caller's.
+func clonePieces(pieces []*Tetromino) []*Tetromino {
+	clones := make([]*Tetromino, len(pieces))
+	for i, t := range pieces {
+		c := *t
+		clones[i] = &c
+	}
+	return clones
+}
@@ -0,0 +1,156 @@
+package solver
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	pieces := func() []*Tetromino {
+		var ts []*Tetromino
+		for i, rows := range [][]string{
+			{"##..", "##..", "....", "...."},
+			{"#...", "#...", "#...", "#..."},
+		} {
+			tt, err := createTestTetromino(rows, i)
+			if err != nil {
+				t.Fatalf("ERROR")
+			}
+			ts = append(ts, tt)
+		}
+		return ts
+	}
+
+	tests := []struct {
+		name     string
+		solution string
+		opts     []Option
+		want     []Violation
+	}{
+		{
+			name:     "Valid",
+			solution: "AAB.\nAAB.\n..B.\n..B.\n",
+		},
+		{
+			name:     "ValidRectangle",
+			solution: "AAB\nAAB\n..B\n..B",
+			opts:     []Option{WithRectangle()},
+		},
+		{
+			name:     "NotSquare",
+			solution: "AAB\nAAB\n..B\n..B",
+			want:     []Violation{{Message: "board is 3x4; want a square"}},
+		},
+		{
+			name:     "WrongWidth",
+			solution: "AAB.\nAAB.\n..B.\n..B.",
+			opts:     []Option{WithWidth(3)},
+			want:     []Violation{{Message: "board is 4 wide; want 3"}},
+		},
+		{
+			name:     "RaggedRow",
+			solution: "AAB.\nAAB\n..B.\n..B.",
+			want:     []Violation{{Line: 2, Column: 4, Message: "row is 3 wide; want 4"}},
+		},
+		{
+			name:     "UnknownLetter",
+			solution: "AAB.\nAAB.\n..B.\n..BC",
+			want:     []Violation{{Line: 4, Column: 4, Letter: 'C', Message: "'C' is not a piece of the puzzle"}},
+		},
+		{
+			name:     "MissingPiece",
+			solution: "AA\nAA",
+			want:     []Violation{{Letter: 'B', Message: "piece B is missing"}},
+		},
+		{
+			name:     "LetterReused",
+			solution: "AA.B\nAA.B\nB...\nBB..",
+			want: []Violation{
+				{Line: 3, Column: 1, Letter: 'B', Message: "letter B marks more than one piece"},
+			},
+		},
+		{
+			name:     "RotatedPiece",
+			solution: "AA..\nAA..\nBBBB\n....",
+			want:     []Violation{{Line: 3, Column: 1, Letter: 'B', Message: "piece B does not have its input shape"}},
+		},
+		{
+			name:     "RotationsAllowed",
+			solution: "AA..\nAA..\nBBBB\n....",
+			opts:     []Option{WithRotations(false)},
+		},
+		{
+			name:     "NotMinimal",
+			solution: "AAB..\nAAB..\n..B..\n..B..\n.....",
+			opts:     []Option{WithMinimalSize()},
+			want:     []Violation{{Message: "board is 5x5; the pieces fit in 4x4"}},
+		},
+		{
+			name:     "Minimal",
+			solution: "AAB.\nAAB.\n..B.\n..B.",
+			opts:     []Option{WithMinimalSize()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(pieces(), tt.solution, tt.opts...)
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("Verify() error = %v; want nil", err)
+				}
+				return
+			}
+			var verr *VerificationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Verify() error = %v; want *VerificationError", err)
+			}
+			if !errors.Is(err, ErrInvalidSolution) {
+				t.Errorf("Verify() error = %v; want it to match ErrInvalidSolution", err)
+			}
+			if !reflect.DeepEqual(verr.Violations, tt.want) {
+				t.Errorf("Verify() violations = %+v; want %+v", verr.Violations, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerificationErrorMessage(t *testing.T) {
+	err := &VerificationError{Violations: []Violation{
+		{Line: 2, Column: 3, Letter: 'B', Message: "piece B does not have its input shape"},
+		{Letter: 'C', Message: "piece C is missing"},
+	}}
+	if want := "2:3: piece B does not have its input shape (and 1 more)"; err.Error() != want {
+		t.Errorf("Error() = %q; want %q", err.Error(), want)
+	}
+}
+
+// repeatedTs returns nine T pieces, which the grid layout packs on 9x9
+// although they fit on 7x7.
+func repeatedTs(t *testing.T) []*Tetromino {
+	t.Helper()
+	puzzle := strings.Repeat("###.\n.#..\n....\n....\n\n", 9)
+	tetrominos, err := parseTetrominos(strings.TrimSuffix(puzzle, "\n"), newOptions(nil))
+	if err != nil {
+		t.Fatalf("parseTetrominos() error = %v", err)
+	}
+	return tetrominos
+}
+
+func TestVerifyMinimalRepeatedPieces(t *testing.T) {
+	grid, err := SolveTetrominos(repeatedTs(t))
+	if err != nil {
+		t.Fatalf("SolveTetrominos() error = %v", err)
+	}
+	err = Verify(repeatedTs(t), grid, WithMinimalSize())
+	var verr *VerificationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Verify() error = %v; want *VerificationError", err)
+	}
+	want := []Violation{{Message: "board is 9x9; the pieces fit in 7x7"}}
+	if !reflect.DeepEqual(verr.Violations, want) {
+		t.Errorf("Verify() violations = %+v; want %+v", verr.Violations, want)
+	}
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"tetris_optimizer/internal/solver"
+	"time"
+)
+
+// Job states, as reported in jobStatus.State.
+const (
+	jobQueued    = "queued"
+	jobRunning   = "running"
+	jobDone      = "done"
+	jobFailed    = "failed"
+	jobCancelled = "cancelled"
+)
+
+// jobRetention is how long a finished job stays available to clients.
+const jobRetention = 10 * time.Minute
+
+// errQueueFull rejects jobs while every queue slot is taken by a job that
+// is still waiting.
+var errQueueFull = errors.New("job queue is full")
+
+// jobManager runs solves in the background on a fixed number of workers,
+// taking jobs from a bounded queue.
+type jobManager struct {
+	timeout time.Duration
+	workers int // goroutines searching each puzzle
+
+	mu        sync.Mutex
+	jobs      map[string]*job
+	queue     []*job // jobs waiting for a worker, oldest first
+	queueSize int
+	wake      chan struct{}
+	stop      context.CancelFunc
+	ctx       context.Context
+	wg        sync.WaitGroup
+}
+
+// newJobManager starts workers goroutines that take jobs from a queue of
+// up to queueSize waiting jobs. Each solve gives up after timeout and is
+// searched by solveWorkers goroutines.
+func newJobManager(workers, queueSize int, timeout time.Duration, solveWorkers int) *jobManager {
+	ctx, stop := context.WithCancel(context.Background())
+	m := &jobManager{
+		timeout:   timeout,
+		workers:   solveWorkers,
+		jobs:      make(map[string]*job),
+		queueSize: queueSize,
+		wake:      make(chan struct{}, 1),
+		ctx:       ctx,
+		stop:      stop,
+	}
+	for i := 0; i < workers; i++ {
+		m.wg.Add(1)
+		go m.work()
+	}
+	return m
+}
+
+// close cancels every job and waits for the workers to stop.
+func (m *jobManager) close() {
+	m.stop()
+	m.wg.Wait()
+}
+
+// submit queues a solve of pieces and returns its job, or errQueueFull.
+func (m *jobManager) submit(pieces []*solver.Tetromino, opts []solver.Option) (*job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.queue) >= m.queueSize {
+		return nil, errQueueFull
+	}
+	ctx, cancel := context.WithCancel(m.ctx)
+	j := &job{
+		id:      newJobID(),
+		pieces:  pieces,
+		opts:    opts,
+		ctx:     ctx,
+		cancel:  cancel,
+		state:   jobQueued,
+		changed: make(chan struct{}),
+	}
+	m.queue = append(m.queue, j)
+	m.jobs[j.id] = j
+	m.signal()
+	return j, nil
+}
+
+// cancel stops j. A job cancelled while queued leaves the queue at once,
+// so it no longer takes a slot.
+func (m *jobManager) cancel(j *job) {
+	m.mu.Lock()
+	for i, queued := range m.queue {
+		if queued == j {
+			m.queue = append(m.queue[:i], m.queue[i+1:]...)
+			m.forget(j)
+			break
+		}
+	}
+	m.mu.Unlock()
+	j.stop()
+}
+
+// get returns the job with the given ID, or nil.
+func (m *jobManager) get(id string) *job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.jobs[id]
+}
+
+// work runs queued jobs until the manager is closed.
+func (m *jobManager) work() {
+	defer m.wg.Done()
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-m.wake:
+			for m.ctx.Err() == nil {
+				j := m.next()
+				if j == nil {
+					break
+				}
+				m.run(j)
+			}
+		}
+	}
+}
+
+// next takes the oldest job off the queue, or returns nil if it is empty.
+// Jobs left behind wake another worker.
+func (m *jobManager) next() *job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.queue) == 0 {
+		return nil
+	}
+	j := m.queue[0]
+	m.queue = m.queue[1:]
+	if len(m.queue) > 0 {
+		m.signal()
+	}
+	return j
+}
+
+// signal wakes a waiting worker, if none is already due to wake.
+func (m *jobManager) signal() {
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+// forget removes j once jobRetention has passed.
+func (m *jobManager) forget(j *job) {
+	time.AfterFunc(jobRetention, func() {
+		m.mu.Lock()
+		delete(m.jobs, j.id)
+		m.mu.Unlock()
+	})
+}
+
+// run solves j unless it was cancelled while queued, then releases its
+// context and forgets it once jobRetention has passed.
+func (m *jobManager) run(j *job) {
+	defer j.cancel()
+	defer m.forget(j)
+	if !j.start() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(j.ctx, m.timeout)
+	defer cancel()
+	var stats solver.Stats
+	opts := append(j.opts[:len(j.opts):len(j.opts)], solver.WithWorkers(m.workers), solver.WithProgress(j.report), solver.WithStats(&stats))
+	start := time.Now()
+	board, err := solver.SolveBoardContext(ctx, j.pieces, opts...)
+	j.finish(board, time.Since(start), stats.Nodes, err)
+}
+
+// job is one background solve.
+type job struct {
+	id     string
+	pieces []*solver.Tetromino
+	opts   []solver.Option
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	state    string
+	progress solver.Progress
+	solution *solver.Solution
+	err      error
+	// changed is closed and replaced whenever the job changes, waking
+	// every client waiting for news.
+	changed chan struct{}
+}
+
+// jobStatus is the JSON form of a job.
+type jobStatus struct {
+	ID       string           `json:"id"`
+	State    string           `json:"state"`
+	Width    int              `json:"width,omitempty"`
+	Height   int              `json:"height,omitempty"`
+	Nodes    int64            `json:"nodes"`
+	Solution *solver.Solution `json:"solution,omitempty"`
+	Error    string           `json:"error,omitempty"`
+}
+
+// status returns the job as clients see it, together with a channel that
+// is closed at its next change.
+func (j *job) status() (jobStatus, <-chan struct{}) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	st := jobStatus{
+		ID:       j.id,
+		State:    j.state,
+		Width:    j.progress.Width,
+		Height:   j.progress.Height,
+		Nodes:    j.progress.Nodes,
+		Solution: j.solution,
+	}
+	if j.err != nil {
+		st.Error = j.err.Error()
+	}
+	return st, j.changed
+}
+
+// finished reports whether the job has reached its final state.
+func (st jobStatus) finished() bool {
+	return st.State == jobDone || st.State == jobFailed || st.State == jobCancelled
+}
+
+// update changes the job under its lock and wakes its watchers.
+func (j *job) update(fn func()) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	fn()
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
+
+// start marks a queued job running, or reports false if it was cancelled.
+func (j *job) start() bool {
+	started := false
+	j.update(func() {
+		if j.state == jobQueued {
+			j.state, started = jobRunning, true
+		}
+	})
+	return started
+}
+
+// report records the solver's progress.
+func (j *job) report(p solver.Progress) {
+	j.update(func() { j.progress = p })
+}
+
+// finish records the outcome of the solve, which tried nodes placements.
+func (j *job) finish(board *solver.Board, elapsed time.Duration, nodes int64, err error) {
+	j.update(func() {
+		j.progress.Nodes = nodes
+		switch {
+		case err == nil:
+			j.state = jobDone
+			j.solution = &solver.Solution{Board: board, Elapsed: elapsed}
+			j.progress.Width, j.progress.Height = board.Width, board.Height
+		case j.state == jobCancelled:
+		default:
+			j.state, j.err = jobFailed, err
+		}
+	})
+}
+
+// stop cancels the job. A finished job is left as it is.
+func (j *job) stop() {
+	j.update(func() {
+		if j.state == jobQueued || j.state == jobRunning {
+			j.state = jobCancelled
+		}
+	})
+	j.cancel()
+}
+
+// newJobID returns a random identifier that cannot be guessed.
+func newJobID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -1,22 +1,244 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"log/slog"
 	"os"
 	"tetris_optimizer/internal/solver"
+	"time"
+)
+
+const usage = "Usage: go run main.go [flags] <filename|->"
+
+// Exit codes reported by the command. Failures to read, validate or solve a
+// puzzle print the ERROR line on stderr; usage errors print a message saying
+// what is wrong instead.
+const (
+	exitOK         = 0 // solved, or help was requested
+	exitInternal   = 1 // any error not covered below
+	exitUsage      = 2 // bad flags or arguments, or options the pieces cannot meet
+	exitUnreadable = 3 // the puzzle could not be read or its path was rejected
+	exitInvalid    = 4 // the puzzle is not valid input
+	exitUnsolved   = 5 // no solution was found in time or within the size bound
+	exitRejected   = 6 // verify found the solution breaks the rules
 )
 
 func main() {
-	if len(os.Args) != 2 {
-		fmt.Fprintln(os.Stderr, "Usage: go run main.go <filename>")
-		os.Exit(0)
+	os.Exit(run(os.Args))
+}
+
+// run executes the command line args and returns the process exit code.
+func run(args []string) int {
+	if len(args) > 1 {
+		switch args[1] {
+		case "count":
+			return runCount(args[2:])
+		case "verify":
+			return runVerify(args[2:])
+		case "generate":
+			return runGenerate(args[2:])
+		case "rate":
+			return runRate(args[2:])
+		case "serve":
+			return runServe(args[2:])
+		}
+	}
+
+	flags := newFlagSet(args[0], usage)
+	timeout := flags.Duration("timeout", 0, "give up solving after this long, e.g. 30s (0 means no limit)")
+	workers := flags.Int("j", 1, "number of goroutines searching in parallel")
+	width := flags.Int("width", 0, "pack into a board of this width and minimize its height")
+	rect := flags.Bool("rect", false, "minimize the area of any rectangle instead of a square")
+	rotate := flags.Bool("rotate", false, "allow pieces to be rotated")
+	mirror := flags.Bool("mirror", false, "allow pieces to be rotated and flipped over")
+	poly := flags.Bool("poly", false, "read pieces of any size from square blocks instead of tetrominoes")
+	sandbox := flags.String("sandbox", "", "only read .txt puzzles from inside this directory, e.g. testfiles")
+	verbose := flags.Bool("verbose", false, "explain errors after the ERROR line")
+	format := flags.String("format", "text", "output format: text, json, svg or png")
+	labels := flags.Bool("labels", false, "write piece letters on drawn boards")
+	gifPath := flags.String("gif", "", "record the search as an animated GIF in this file")
+	colorMode := flags.String("color", "auto", "colour text output: auto (when stdout is a terminal), always or never")
+	showStats := flags.Bool("stats", false, "print search statistics to stderr")
+	logLevel := flags.String("log", "", "log solver progress to stderr at this level: debug or info (empty means off)")
+	if err := flags.Parse(args[1:]); err != nil {
+		return parseExitCode(err)
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, usage)
+		return exitUsage
+	}
+	write, ok := writers[*format]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", *format)
+		return exitUsage
+	}
+	color, err := useColor(*colorMode, os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	if err := checkWidth(*width); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	logger, err := newLogger(*logLevel)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+
+	ctx, cancel := timeoutContext(*timeout)
+	defer cancel()
+
+	opts := []solver.Option{solver.WithWorkers(*workers)}
+	if *width > 0 {
+		opts = append(opts, solver.WithWidth(*width))
+	}
+	if *rect {
+		opts = append(opts, solver.WithRectangle())
+	}
+	if *rotate || *mirror {
+		opts = append(opts, solver.WithRotations(*mirror))
+	}
+	if *poly {
+		opts = append(opts, solver.WithPolyominoes())
+	}
+	if *sandbox != "" {
+		opts = append(opts, solver.WithSandbox(*sandbox))
 	}
 
-	solution, err := solver.Validate(os.Args[1])
+	tetrominos, err := readPuzzle(flags.Arg(0), opts)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "ERROR")
-		os.Exit(0)
+		return reportError(err, *verbose)
+	}
+	var rec *solver.GIFRecorder
+	if *gifPath != "" {
+		rec = solver.NewGIFRecorder(solver.GIFOptions{})
+		opts = append(opts, solver.WithTrace(rec.Record))
+	}
+
+	var stats solver.Stats
+	if *showStats {
+		opts = append(opts, solver.WithStats(&stats))
+	}
+	if logger != nil {
+		opts = append(opts, solver.WithLogger(logger))
 	}
 
-	fmt.Println(solution)
-}
\ No newline at end of file
+	start := time.Now()
+	board, err := solver.SolveBoardContext(ctx, tetrominos, opts...)
+	if *showStats {
+		// Statistics matter most when solving fails or times out.
+		stats.WriteTo(os.Stderr)
+	}
+	if err != nil {
+		return reportError(err, *verbose)
+	}
+	if rec != nil {
+		if err := writeGIF(*gifPath, rec, board); err != nil {
+			return reportError(err, *verbose)
+		}
+	}
+
+	sol := solver.Solution{Board: board, Elapsed: time.Since(start)}
+	if err := write(os.Stdout, sol, outputOptions{labels: *labels, color: color}); err != nil {
+		return reportError(err, *verbose)
+	}
+	return exitOK
+}
+
+// reportError prints the plain ERROR line the spec requires, followed by
+// the error itself when verbose is set, and returns the exit code for err.
+func reportError(err error, verbose bool) int {
+	fmt.Fprintln(os.Stderr, "ERROR")
+	if verbose {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	return exitCode(err)
+}
+
+// exitCode maps a solver error to the exit code documented above.
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, solver.ErrInvalidOption):
+		return exitUsage
+	case errors.Is(err, solver.ErrUnreadable), errors.Is(err, solver.ErrPathRejected):
+		return exitUnreadable
+	case errors.Is(err, solver.ErrInvalidFormat):
+		return exitInvalid
+	case errors.Is(err, solver.ErrUnsolvable), errors.Is(err, solver.ErrTimeout), errors.Is(err, solver.ErrNoPuzzle):
+		return exitUnsolved
+	case errors.Is(err, solver.ErrInvalidSolution):
+		return exitRejected
+	}
+	return exitInternal
+}
+
+// parseExitCode returns the exit code for a flag parsing error, which the
+// flag set has already reported.
+func parseExitCode(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	return exitUsage
+}
+
+// readPuzzle reads the puzzle named on the command line, where "-" means
+// standard input.
+func readPuzzle(name string, opts []solver.Option) ([]*solver.Tetromino, error) {
+	if name == "-" {
+		tetrominos, err := solver.Parse(os.Stdin, opts...)
+		var perr *solver.ParseError
+		if errors.As(err, &perr) {
+			perr.File = "<stdin>"
+		}
+		return tetrominos, err
+	}
+	return solver.ReadTetrominos(name, opts...)
+}
+
+// newFlagSet returns a flag set that reports errors on stderr with usage.
+func newFlagSet(name, usage string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, usage)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// checkWidth rejects a -width no board can have, before any puzzle is read.
+func checkWidth(width int) error {
+	if width < 0 || width > solver.MaxWidth {
+		return fmt.Errorf("width must be between 1 and %d, got %d", solver.MaxWidth, width)
+	}
+	return nil
+}
+
+// newLogger returns a logger writing to stderr from the named level up, or
+// nil when level is empty.
+func newLogger(level string) (*slog.Logger, error) {
+	if level == "" {
+		return nil, nil
+	}
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("unknown log level %q", level)
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: l})), nil
+}
+
+// timeoutContext returns a context that expires after timeout, or never
+// when timeout is zero.
+func timeoutContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout > 0 {
+		return context.WithTimeout(context.Background(), timeout)
+	}
+	return context.WithCancel(context.Background())
+}
@@ -2,9 +2,15 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestMain(t *testing.T) {
@@ -19,24 +25,247 @@ func TestMain(t *testing.T) {
 		t.Fatalf("Failed to create test file: %v", err)
 	}
 
+	polyFileContent := "###\n...\n...\n\n.#.\n##.\n..."
+	polyFilePath := filepath.Join(tmpDir, "testfiles", "poly.txt")
+	if err := os.WriteFile(polyFilePath, []byte(polyFileContent), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
 	tests := []struct {
 		name       string
 		args       []string
+		stdin      string
 		wantOutput string
+		wantPrefix bool // wantOutput is only the start of the output
 		wantExit   int
 	}{
 		{
 			name:       "ValidFile",
-			args:       []string{"program", "test.txt"},
+			args:       []string{"program", "testfiles/test.txt"},
+			wantOutput: "AA\nAA\n",
+			wantExit:   0,
+		},
+		{
+			name:       "AbsolutePath",
+			args:       []string{"program", testFilePath},
+			wantOutput: "AA\nAA\n",
+			wantExit:   0,
+		},
+		{
+			name:       "Stdin",
+			args:       []string{"program", "-"},
+			stdin:      testFileContent,
+			wantOutput: "AA\nAA\n",
+			wantExit:   0,
+		},
+		{
+			name:       "SandboxFlag",
+			args:       []string{"program", "-sandbox", "testfiles", "test.txt"},
+			wantOutput: "AA\nAA\n",
+			wantExit:   0,
+		},
+		{
+			name:       "SandboxRejectsTraversal",
+			args:       []string{"program", "-sandbox", "testfiles", "../testfiles/../main.txt"},
+			wantOutput: "ERROR\n",
+			wantExit:   exitUnreadable,
+		},
+		{
+			name:       "InvalidFile",
+			args:       []string{"program", "-"},
+			stdin:      "##..\n#x..\n....\n....",
+			wantOutput: "ERROR\n",
+			wantExit:   exitInvalid,
+		},
+		{
+			name:       "VerboseFlag",
+			args:       []string{"program", "-verbose", "-"},
+			stdin:      "##..\n#x..\n....\n....",
+			wantOutput: "ERROR\n<stdin>:2:2: block 1: bad character\n",
+			wantExit:   exitInvalid,
+		},
+		{
+			name:       "TimeoutFlag",
+			args:       []string{"program", "-timeout", "1m", "testfiles/test.txt"},
 			wantOutput: "AA\nAA\n",
 			wantExit:   0,
 		},
+		{
+			name:       "ParallelFlag",
+			args:       []string{"program", "-j", "4", "testfiles/test.txt"},
+			wantOutput: "AA\nAA\n",
+			wantExit:   0,
+		},
+		{
+			name:       "WidthFlag",
+			args:       []string{"program", "-width", "3", "testfiles/test.txt"},
+			wantOutput: "AA.\nAA.\n",
+			wantExit:   0,
+		},
+		{
+			name:       "PolyFlag",
+			args:       []string{"program", "-poly", "testfiles/poly.txt"},
+			wantOutput: ".B.\nBB.\nAAA\n",
+			wantExit:   0,
+		},
+		{
+			name:       "JSONFormat",
+			args:       []string{"program", "-format", "json", "testfiles/test.txt"},
+			wantOutput: `{"size":2,"width":2,"height":2,"rows":["AA","AA"],"pieces":[{"letter":"A","index":0,"x":0,"y":0,"cells":[{"x":0,"y":0},{"x":1,"y":0},{"x":0,"y":1},{"x":1,"y":1}]}],"empty":0,"elapsedMs":`,
+			wantPrefix: true,
+			wantExit:   0,
+		},
+		{
+			name:       "SVGFormat",
+			args:       []string{"program", "-format", "svg", "-labels", "testfiles/test.txt"},
+			wantOutput: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<svg xmlns="http://www.w3.org/2000/svg" width="86" height="86" viewBox="-3 -3 86 86">`,
+			wantPrefix: true,
+			wantExit:   0,
+		},
+		{
+			name:       "PNGFormat",
+			args:       []string{"program", "-format", "png", "testfiles/test.txt"},
+			wantOutput: "\x89PNG\r\n\x1a\n",
+			wantPrefix: true,
+			wantExit:   0,
+		},
+		{
+			name:       "GIFFlag",
+			args:       []string{"program", "-gif", "search.gif", "testfiles/test.txt"},
+			wantOutput: "AA\nAA\n",
+			wantExit:   0,
+		},
+		{
+			name:       "ColorAlways",
+			args:       []string{"program", "-color", "always", "testfiles/test.txt"},
+			wantOutput: "┌───────┐\n│\x1b[30;48;2;219;87;87m A \x1b[0m",
+			wantPrefix: true,
+			wantExit:   0,
+		},
+		{
+			name:       "ColorAutoWhenPiped",
+			args:       []string{"program", "-color", "auto", "testfiles/test.txt"},
+			wantOutput: "AA\nAA\n",
+			wantExit:   0,
+		},
+		{
+			name:       "UnknownColorMode",
+			args:       []string{"program", "-color", "sometimes", "testfiles/test.txt"},
+			wantOutput: "unknown color mode \"sometimes\"\n",
+			wantExit:   exitUsage,
+		},
+		{
+			name:       "UnknownFormat",
+			args:       []string{"program", "-format", "xml", "testfiles/test.txt"},
+			wantOutput: "unknown format \"xml\"\n",
+			wantExit:   exitUsage,
+		},
+		{
+			name:       "VerifyCommand",
+			args:       []string{"program", "verify", "testfiles/test.txt", "-"},
+			stdin:      "AA\nAA\n",
+			wantOutput: "OK\n",
+			wantExit:   0,
+		},
+		{
+			name:       "VerifyCommandRejects",
+			args:       []string{"program", "verify", "-minimal", "testfiles/test.txt", "-"},
+			stdin:      "AA.\nAA.\n...\n",
+			wantOutput: "ERROR\nboard is 3x3; the pieces fit in 2x2\n",
+			wantExit:   exitRejected,
+		},
+		{
+			name:       "VerifyCommandUsage",
+			args:       []string{"program", "verify", "testfiles/test.txt"},
+			wantOutput: verifyUsage + "\n",
+			wantExit:   exitUsage,
+		},
+		{
+			name:       "GenerateCommand",
+			args:       []string{"program", "generate", "-n", "2", "-seed", "3", "-shapes", "O=1"},
+			wantOutput: "##..\n##..\n....\n....\n\n##..\n##..\n....\n....\n",
+			wantExit:   0,
+		},
+		{
+			name:       "GenerateCommandBadShapes",
+			args:       []string{"program", "generate", "-shapes", "I"},
+			wantOutput: "invalid shape weight \"I\", want NAME=WEIGHT\n",
+			wantExit:   exitUsage,
+		},
+		{
+			name:       "RateCommand",
+			args:       []string{"program", "rate", "testfiles/test.txt"},
+			wantOutput: "easy\npieces      1\nboard       2x2\nslack       0\nnodes       1\nbacktracks  0\nmax depth   1\n",
+			wantExit:   0,
+		},
+		{
+			name:       "RateCommandJSON",
+			args:       []string{"program", "rate", "-format", "json", "testfiles/test.txt"},
+			wantOutput: `{"difficulty":"easy","pieces":1,"width":2,"height":2,"slack":0,"stats":{"nodes":1,"backtracks":0,"maxDepth":1,"places":1,"removes":0,"pruned":0}}` + "\n",
+			wantExit:   0,
+		},
+		{
+			name:       "RateCommandBadFormat",
+			args:       []string{"program", "rate", "-format", "svg", "testfiles/test.txt"},
+			wantOutput: "unknown format \"svg\"\n",
+			wantExit:   exitUsage,
+		},
+		{
+			name:       "CountCommand",
+			args:       []string{"program", "count", "-identical", "testfiles/test.txt"},
+			wantOutput: "1\n",
+			wantExit:   0,
+		},
 		{
 			name:       "NoArguments",
 			args:       []string{"program"},
-			wantOutput: "Usage: go run main.go <filename>\n",
+			wantOutput: "Usage: go run main.go [flags] <filename|->\n",
+			wantExit:   exitUsage,
+		},
+		{
+			name:       "MissingFile",
+			args:       []string{"program", "testfiles/missing.txt"},
+			wantOutput: "ERROR\n",
+			wantExit:   exitUnreadable,
+		},
+		{
+			name:       "WidthTooWide",
+			args:       []string{"program", "-width", "200", "testfiles/test.txt"},
+			wantOutput: "width must be between 1 and 64, got 200\n",
+			wantExit:   exitUsage,
+		},
+		{
+			name:       "WidthTooNarrow",
+			args:       []string{"program", "-verbose", "-width", "1", "testfiles/test.txt"},
+			wantOutput: "ERROR\nwidth 1 is narrower than a piece 2 wide\n",
+			wantExit:   exitUsage,
+		},
+		{
+			name:       "Stats",
+			args:       []string{"program", "-stats", "testfiles/test.txt"},
+			wantOutput: "AA\nAA\nnodes       1\nplaces      1\nremoves     0\nbacktracks  0\npruned      0\nmax depth   1\noptimized   ",
+			wantPrefix: true,
 			wantExit:   0,
 		},
+		{
+			name:       "LogLevel",
+			args:       []string{"program", "-log", "info", "testfiles/test.txt"},
+			wantOutput: "AA\nAA\ntime=",
+			wantPrefix: true,
+			wantExit:   0,
+		},
+		{
+			name:       "BadLogLevel",
+			args:       []string{"program", "-log", "loud", "testfiles/test.txt"},
+			wantOutput: "unknown log level \"loud\"\n",
+			wantExit:   exitUsage,
+		},
+		{
+			name:       "Timeout",
+			args:       []string{"program", "-timeout", "1ns", "testfiles/test.txt"},
+			wantOutput: "ERROR\n",
+			wantExit:   exitUnsolved,
+		},
 	}
 
 	for _, tt := range tests {
@@ -46,6 +275,16 @@ func TestMain(t *testing.T) {
 			os.Chdir(tmpDir)
 			defer os.Chdir(origDir)
 
+			// Feed stdin
+			oldStdin := os.Stdin
+			rIn, wIn, _ := os.Pipe()
+			go func() {
+				wIn.WriteString(tt.stdin)
+				wIn.Close()
+			}()
+			os.Stdin = rIn
+			defer func() { os.Stdin = oldStdin }()
+
 			// Redirect stdout
 			oldStdout := os.Stdout
 			r, w, _ := os.Pipe()
@@ -58,19 +297,8 @@ func TestMain(t *testing.T) {
 			os.Stderr = wErr
 			defer func() { os.Stderr = oldStderr }()
 
-			// Set args and run main
-			os.Args = tt.args
-			var exitCode int
-			func() {
-				defer func() {
-					if r := recover(); r != nil {
-						if code, ok := r.(int); ok {
-							exitCode = code
-						}
-					}
-				}()
-				main()
-			}()
+			// Run the command line
+			exitCode := run(tt.args)
 
 			// Capture output
 			w.Close()
@@ -80,12 +308,253 @@ func TestMain(t *testing.T) {
 			_, _ = stderrBuf.ReadFrom(rErr)
 
 			output := stdoutBuf.String() + stderrBuf.String()
+			if tt.wantPrefix && strings.HasPrefix(output, tt.wantOutput) {
+				output = tt.wantOutput
+			}
 			if output != tt.wantOutput {
-				t.Errorf("main() output = %q; want %q", output, tt.wantOutput)
+				t.Errorf("run() output = %q; want %q", output, tt.wantOutput)
 			}
 			if exitCode != tt.wantExit {
-				t.Errorf("main() exit code = %d; want %d", exitCode, tt.wantExit)
+				t.Errorf("run() exit code = %d; want %d", exitCode, tt.wantExit)
+			}
+		})
+	}
+}
+
+func TestServe(t *testing.T) {
+	s := &server{timeout: time.Second, maxPieces: 2, maxBytes: 64, workers: 1}
+	square := "##..\n##..\n....\n....\n"
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		contentType string
+		body        string
+		wantStatus  int
+		wantBody    string
+	}{
+		{
+			name:       "Health",
+			method:     "GET",
+			path:       "/healthz",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"status":"ok"}`,
+		},
+		{
+			name:       "SolveText",
+			method:     "POST",
+			path:       "/solve",
+			body:       square,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"size":2,"width":2,"height":2,"rows":["AA","AA"],`,
+		},
+		{
+			name:        "SolveJSON",
+			method:      "POST",
+			path:        "/solve?rotate=true",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"pieces":[["####","....","....","...."]]}`,
+			wantStatus:  http.StatusOK,
+			wantBody:    `{"size":4,"width":4,"height":4,"rows":["AAAA","....","....","...."],`,
+		},
+		{
+			name:        "SolveBadJSON",
+			method:      "POST",
+			path:        "/solve",
+			contentType: "application/json",
+			body:        `{"shapes":[]}`,
+			wantStatus:  http.StatusBadRequest,
+			wantBody:    `{"error":"invalid JSON piece list: json: unknown field \"shapes\""}`,
+		},
+		{
+			name:       "SolveWidthTooNarrow",
+			method:     "POST",
+			path:       "/solve?width=2",
+			body:       "####\n....\n....\n....\n",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"width 2 is narrower than a piece 4 wide"}`,
+		},
+		{
+			name:       "SolveWidthTooWide",
+			method:     "POST",
+			path:       "/solve?width=65",
+			body:       square,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"invalid width \"65\""}`,
+		},
+		{
+			name:       "SolveBadOption",
+			method:     "POST",
+			path:       "/solve?rotate=maybe",
+			body:       square,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"invalid rotate \"maybe\""}`,
+		},
+		{
+			name:       "TooManyPieces",
+			method:     "POST",
+			path:       "/solve",
+			body:       square + "\n" + square + "\n" + square,
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantBody:   `{"error":"request too large: 3 pieces; the limit is 2"}`,
+		},
+		{
+			name:       "TooManyBytes",
+			method:     "POST",
+			path:       "/validate",
+			body:       strings.Repeat(".", 65),
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantBody:   `{"error":"request too large: body is over 64 bytes"}`,
+		},
+		{
+			name:       "Validate",
+			method:     "POST",
+			path:       "/validate",
+			body:       square,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"valid":true,"pieces":1}`,
+		},
+		{
+			name:       "ValidateParseError",
+			method:     "POST",
+			path:       "/validate",
+			body:       "##..\n##.x\n....\n....\n",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"2:4: block 1: bad character","line":2,"column":4,"block":1}`,
+		},
+		{
+			name:       "WrongMethod",
+			method:     "GET",
+			path:       "/solve",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+			s.routes().ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d; want %d (body %q)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if !strings.HasPrefix(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %q; want prefix %q", rec.Body.String(), tt.wantBody)
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestServeTimeout(t *testing.T) {
+	s := &server{timeout: time.Nanosecond, maxPieces: 26, maxBytes: 1 << 10, workers: 1}
+	req := httptest.NewRequest("POST", "/solve", strings.NewReader("##..\n##..\n....\n....\n"))
+	rec := httptest.NewRecorder()
+	s.routes().ServeHTTP(rec, req)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d; want %d (body %q)", rec.Code, http.StatusServiceUnavailable, rec.Body.String())
+	}
+}
+
+func TestJobs(t *testing.T) {
+	jobs := newJobManager(1, 1, time.Minute, 1)
+	defer jobs.close()
+	srv := httptest.NewServer((&server{timeout: time.Second, maxPieces: 26, maxBytes: 1 << 10, jobs: jobs}).routes())
+	defer srv.Close()
+
+	puzzle, err := os.ReadFile(filepath.Join("testfiles", "g04.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Post(srv.URL+"/jobs", "text/plain", bytes.NewReader(puzzle))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var submitted jobStatus
+	json.NewDecoder(resp.Body).Decode(&submitted)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted || submitted.ID == "" {
+		t.Fatalf("POST /jobs = %d, %+v; want 202 and a job", resp.StatusCode, submitted)
+	}
+	if loc := resp.Header.Get("Location"); loc != "/jobs/"+submitted.ID {
+		t.Errorf("Location = %q; want /jobs/%s", loc, submitted.ID)
+	}
+
+	// The event stream ends with the finished job.
+	resp, err = http.Get(srv.URL + "/jobs/" + submitted.ID + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	events, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("events Content-Type = %q; want text/event-stream", ct)
+	}
+	blocks := strings.Split(strings.TrimSpace(string(events)), "\n\n")
+	if last := blocks[len(blocks)-1]; !strings.HasPrefix(last, "event: done\ndata: {") {
+		t.Errorf("last event = %q; want the done job", last)
+	}
+
+	resp, err = http.Get(srv.URL + "/jobs/" + submitted.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var done jobStatus
+	json.NewDecoder(resp.Body).Decode(&done)
+	resp.Body.Close()
+	if done.State != jobDone || done.Solution == nil || done.Width != 7 || done.Nodes == 0 {
+		t.Errorf("GET /jobs/{id} = %+v; want a done job solved on 7x7", done)
+	}
+	// A finished job releases its context.
+	select {
+	case <-jobs.get(submitted.ID).ctx.Done():
+	case <-time.After(time.Second):
+		t.Error("finished job's context was not cancelled")
+	}
+
+	resp, err = http.Get(srv.URL + "/jobs/unknown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET /jobs/unknown = %d; want 404", resp.StatusCode)
+	}
+}
+
+func TestJobsQueue(t *testing.T) {
+	// With no workers jobs stay queued until cancelled.
+	jobs := newJobManager(0, 1, time.Minute, 1)
+	defer jobs.close()
+	s := &server{timeout: time.Second, maxPieces: 26, maxBytes: 1 << 10, jobs: jobs}
+	submit := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		s.routes().ServeHTTP(rec, httptest.NewRequest("POST", "/jobs", strings.NewReader("##..\n##..\n....\n....\n")))
+		return rec
+	}
+
+	rec := submit()
+	var queued jobStatus
+	json.NewDecoder(rec.Body).Decode(&queued)
+	if rec.Code != http.StatusAccepted || queued.State != jobQueued {
+		t.Fatalf("POST /jobs = %d, %+v; want 202 and a queued job", rec.Code, queued)
+	}
+	if rec := submit(); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("POST /jobs on a full queue = %d; want 503", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	s.routes().ServeHTTP(rec, httptest.NewRequest("DELETE", "/jobs/"+queued.ID, nil))
+	var cancelled jobStatus
+	json.NewDecoder(rec.Body).Decode(&cancelled)
+	if rec.Code != http.StatusOK || cancelled.State != jobCancelled {
+		t.Errorf("DELETE /jobs/{id} = %d, %+v; want 200 and a cancelled job", rec.Code, cancelled)
+	}
+	// The cancelled job gave up its slot.
+	if rec := submit(); rec.Code != http.StatusAccepted {
+		t.Errorf("POST /jobs after cancelling the queued job = %d; want 202", rec.Code)
+	}
+}
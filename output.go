@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"tetris_optimizer/internal/solver"
+)
+
+// outputOptions holds the flags that tune how solutions are drawn.
+type outputOptions struct {
+	labels bool
+	color  bool
+}
+
+// writers renders a solution for each -format value.
+var writers = map[string]func(io.Writer, solver.Solution, outputOptions) error{
+	"text": writeText,
+	"json": writeJSON,
+	"svg":  writeSVG,
+	"png":  writePNG,
+}
+
+// writeText prints the board as rows of letters and dots, or with colours
+// and box-drawing borders when o.color is set.
+func writeText(w io.Writer, sol solver.Solution, o outputOptions) error {
+	if o.color {
+		return solver.RenderANSI(w, sol.Board)
+	}
+	_, err := fmt.Fprintln(w, sol.Board)
+	return err
+}
+
+// writeJSON prints the board, its placements and the solve time as one
+// JSON object.
+func writeJSON(w io.Writer, sol solver.Solution, _ outputOptions) error {
+	return json.NewEncoder(w).Encode(sol)
+}
+
+// writeSVG draws the board as an SVG image.
+func writeSVG(w io.Writer, sol solver.Solution, o outputOptions) error {
+	return solver.RenderSVG(w, sol.Board, solver.SVGOptions{Labels: o.labels})
+}
+
+// writePNG draws the board as a PNG image.
+func writePNG(w io.Writer, sol solver.Solution, _ outputOptions) error {
+	return solver.RenderPNG(w, sol.Board, solver.ImageOptions{})
+}
+
+// writeGIF saves the recorded search, ending on the solved board, to path.
+func writeGIF(path string, rec *solver.GIFRecorder, final *solver.Board) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := rec.Encode(f, final); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// useColor resolves the -color mode for out. In auto mode colour is used
+// when out is a terminal and NO_COLOR is unset.
+func useColor(mode string, out *os.File) (bool, error) {
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto":
+		if os.Getenv("NO_COLOR") != "" {
+			return false, nil
+		}
+		info, err := out.Stat()
+		return err == nil && info.Mode()&os.ModeCharDevice != 0, nil
+	}
+	return false, fmt.Errorf("unknown color mode %q", mode)
+}
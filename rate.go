@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"tetris_optimizer/internal/solver"
+)
+
+const rateUsage = "Usage: go run main.go rate [flags] <filename|->"
+
+// runRate grades how hard the puzzle is to solve and returns the process
+// exit code.
+func runRate(args []string) int {
+	flags := newFlagSet("rate", rateUsage)
+	timeout := flags.Duration("timeout", 0, "give up solving after this long, e.g. 30s (0 means no limit)")
+	format := flags.String("format", "text", "output format: text or json")
+	poly := flags.Bool("poly", false, "read pieces of any size from square blocks instead of tetrominoes")
+	sandbox := flags.String("sandbox", "", "only read .txt puzzles from inside this directory, e.g. testfiles")
+	verbose := flags.Bool("verbose", false, "explain errors after the ERROR line")
+	if err := flags.Parse(args); err != nil {
+		return parseExitCode(err)
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, rateUsage)
+		return exitUsage
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", *format)
+		return exitUsage
+	}
+
+	ctx, cancel := timeoutContext(*timeout)
+	defer cancel()
+
+	var opts []solver.Option
+	if *poly {
+		opts = append(opts, solver.WithPolyominoes())
+	}
+	if *sandbox != "" {
+		opts = append(opts, solver.WithSandbox(*sandbox))
+	}
+	tetrominos, err := readPuzzle(flags.Arg(0), opts)
+	if err != nil {
+		return reportError(err, *verbose)
+	}
+	r, err := solver.RateContext(ctx, tetrominos)
+	if err != nil {
+		return reportError(err, *verbose)
+	}
+
+	if *format == "json" {
+		if err := json.NewEncoder(os.Stdout).Encode(r); err != nil {
+			return reportError(err, *verbose)
+		}
+		return exitOK
+	}
+	fmt.Println(r.Difficulty)
+	fmt.Printf("pieces      %d\n", r.Pieces)
+	fmt.Printf("board       %dx%d\n", r.Width, r.Height)
+	fmt.Printf("slack       %d\n", r.Slack)
+	fmt.Printf("nodes       %d\n", r.Stats.Nodes)
+	fmt.Printf("backtracks  %d\n", r.Stats.Backtracks)
+	fmt.Printf("max depth   %d\n", r.Stats.MaxDepth)
+	return exitOK
+}
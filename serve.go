@@ -0,0 +1,367 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"tetris_optimizer/internal/solver"
+	"time"
+)
+
+const serveUsage = "Usage: go run main.go serve [flags]"
+
+// runServe answers solve, validate and job requests over HTTP until
+// interrupted and returns the process exit code.
+func runServe(args []string) int {
+	flags := newFlagSet("serve", serveUsage)
+	addr := flags.String("addr", ":8080", "address to listen on")
+	timeout := flags.Duration("timeout", 10*time.Second, "give up each solve after this long")
+	maxPieces := flags.Int("max-pieces", 26, "reject puzzles with more pieces than this")
+	maxBytes := flags.Int64("max-bytes", 64<<10, "reject request bodies larger than this many bytes")
+	workers := flags.Int("j", 1, "number of goroutines searching each puzzle in parallel")
+	jobWorkers := flags.Int("job-workers", 2, "number of jobs solved at once")
+	queueSize := flags.Int("queue", 64, "number of jobs that may wait for a worker")
+	jobTimeout := flags.Duration("job-timeout", 10*time.Minute, "give up each job after this long")
+	if err := flags.Parse(args); err != nil {
+		return parseExitCode(err)
+	}
+	if flags.NArg() != 0 || *timeout <= 0 || *maxPieces < 1 || *maxBytes < 1 || *jobWorkers < 1 || *queueSize < 0 || *jobTimeout <= 0 {
+		fmt.Fprintln(os.Stderr, serveUsage)
+		return exitUsage
+	}
+
+	s := &server{
+		timeout:   *timeout,
+		maxPieces: *maxPieces,
+		maxBytes:  *maxBytes,
+		workers:   *workers,
+		jobs:      newJobManager(*jobWorkers, *queueSize, *jobTimeout, *workers),
+	}
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           s.routes(),
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		// Leave the solver its full timeout before the connection is cut.
+		WriteTimeout: *timeout + 30*time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe() }()
+	fmt.Fprintf(os.Stderr, "listening on %s\n", *addr)
+
+	select {
+	case err := <-errc:
+		s.jobs.close()
+		return reportError(err, true)
+	case <-ctx.Done():
+	}
+	// Cancelling the jobs ends their event streams, so the server can go
+	// idle and shut down.
+	s.jobs.close()
+	shutdown, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdown); err != nil {
+		return reportError(err, true)
+	}
+	return exitOK
+}
+
+// server holds the limits applied to every request.
+type server struct {
+	timeout   time.Duration
+	maxPieces int
+	maxBytes  int64
+	workers   int
+	jobs      *jobManager
+}
+
+// routes returns the handler for every endpoint of the service.
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /solve", s.handleSolve)
+	mux.HandleFunc("POST /validate", s.handleValidate)
+	mux.HandleFunc("GET /healthz", s.handleHealth)
+	mux.HandleFunc("POST /jobs", s.handleSubmitJob)
+	mux.HandleFunc("GET /jobs/{id}", s.handleJob)
+	mux.HandleFunc("DELETE /jobs/{id}", s.handleCancelJob)
+	mux.HandleFunc("GET /jobs/{id}/events", s.handleJobEvents)
+	return mux
+}
+
+// handleSolve answers with the solution as -format json prints it.
+func (s *server) handleSolve(w http.ResponseWriter, r *http.Request) {
+	opts, err := requestOptions(r.URL.Query())
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+	tetrominos, err := s.readPieces(w, r, opts)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.timeout)
+	defer cancel()
+	start := time.Now()
+	board, err := solver.SolveBoardContext(ctx, tetrominos, append(opts, solver.WithWorkers(s.workers))...)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+	respond(w, http.StatusOK, solver.Solution{Board: board, Elapsed: time.Since(start)})
+}
+
+// handleValidate reads the puzzle without solving it.
+func (s *server) handleValidate(w http.ResponseWriter, r *http.Request) {
+	opts, err := requestOptions(r.URL.Query())
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+	tetrominos, err := s.readPieces(w, r, opts)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+	respond(w, http.StatusOK, struct {
+		Valid  bool `json:"valid"`
+		Pieces int  `json:"pieces"`
+	}{true, len(tetrominos)})
+}
+
+func (s *server) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	respond(w, http.StatusOK, struct {
+		Status string `json:"status"`
+	}{"ok"})
+}
+
+// handleSubmitJob queues the puzzle for a background solve and answers
+// with the new job.
+func (s *server) handleSubmitJob(w http.ResponseWriter, r *http.Request) {
+	opts, err := requestOptions(r.URL.Query())
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+	tetrominos, err := s.readPieces(w, r, opts)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+	j, err := s.jobs.submit(tetrominos, opts)
+	if err != nil {
+		respondError(w, err)
+		return
+	}
+	st, _ := j.status()
+	w.Header().Set("Location", "/jobs/"+st.ID)
+	respond(w, http.StatusAccepted, st)
+}
+
+// handleJob answers with the state of a job.
+func (s *server) handleJob(w http.ResponseWriter, r *http.Request) {
+	j := s.jobs.get(r.PathValue("id"))
+	if j == nil {
+		respondError(w, errNoJob)
+		return
+	}
+	st, _ := j.status()
+	respond(w, http.StatusOK, st)
+}
+
+// handleCancelJob cancels a job and answers with its state.
+func (s *server) handleCancelJob(w http.ResponseWriter, r *http.Request) {
+	j := s.jobs.get(r.PathValue("id"))
+	if j == nil {
+		respondError(w, errNoJob)
+		return
+	}
+	s.jobs.cancel(j)
+	st, _ := j.status()
+	respond(w, http.StatusOK, st)
+}
+
+// eventInterval is the least time between two events of a stream, so
+// fast progress is sent in batches.
+const eventInterval = 100 * time.Millisecond
+
+// handleJobEvents streams the state of a job as Server-Sent Events until
+// it finishes. Each event is named after the job's state and carries the
+// same JSON as GET /jobs/{id}.
+func (s *server) handleJobEvents(w http.ResponseWriter, r *http.Request) {
+	j := s.jobs.get(r.PathValue("id"))
+	if j == nil {
+		respondError(w, errNoJob)
+		return
+	}
+	rc := http.NewResponseController(w)
+	// The stream lasts as long as the job, past the server's write timeout.
+	rc.SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	for {
+		st, changed := j.status()
+		data, err := json.Marshal(st)
+		if err != nil {
+			return
+		}
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", st.State, data)
+		if err := rc.Flush(); err != nil || st.finished() {
+			return
+		}
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return
+		}
+		select {
+		case <-time.After(eventInterval):
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// pieceList is the JSON form of a puzzle: the rows of each piece's block,
+// as they would appear in the text format.
+type pieceList struct {
+	Pieces [][]string `json:"pieces"`
+}
+
+var (
+	// errTooLarge rejects requests over the server's limits.
+	errTooLarge = errors.New("request too large")
+	// errNoJob answers requests for unknown or forgotten jobs.
+	errNoJob = errors.New("no such job")
+)
+
+// readPieces reads the puzzle in the request body, which is the text
+// format unless the content type is application/json.
+func (s *server) readPieces(w http.ResponseWriter, r *http.Request, opts []solver.Option) ([]*solver.Tetromino, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.maxBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, fmt.Errorf("%w: body is over %d bytes", errTooLarge, tooLarge.Limit)
+		}
+		return nil, solver.NewValidationError(solver.ErrUnreadable, "error reading request body")
+	}
+
+	text := string(body)
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/json" {
+		var list pieceList
+		dec := json.NewDecoder(strings.NewReader(text))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&list); err != nil {
+			return nil, solver.NewValidationError(solver.ErrInvalidFormat, "invalid JSON piece list: "+err.Error())
+		}
+		blocks := make([]string, len(list.Pieces))
+		for i, rows := range list.Pieces {
+			blocks[i] = strings.Join(rows, "\n")
+		}
+		text = strings.Join(blocks, "\n\n")
+	}
+
+	tetrominos, err := solver.Parse(strings.NewReader(text), opts...)
+	if err != nil {
+		return nil, err
+	}
+	if len(tetrominos) > s.maxPieces {
+		return nil, fmt.Errorf("%w: %d pieces; the limit is %d", errTooLarge, len(tetrominos), s.maxPieces)
+	}
+	return tetrominos, nil
+}
+
+// requestOptions reads the solver options from the query string: width,
+// rect, rotate, mirror and poly, as the command line flags of those names.
+func requestOptions(q url.Values) ([]solver.Option, error) {
+	var opts []solver.Option
+	if v := q.Get("width"); v != "" {
+		width, err := strconv.Atoi(v)
+		if err != nil || width < 1 || width > solver.MaxWidth {
+			return nil, solver.NewValidationError(solver.ErrInvalidOption, fmt.Sprintf("invalid width %q", v))
+		}
+		opts = append(opts, solver.WithWidth(width))
+	}
+	flags := map[string]bool{}
+	for _, name := range []string{"rect", "rotate", "mirror", "poly"} {
+		if v := q.Get(name); v != "" {
+			on, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, solver.NewValidationError(solver.ErrInvalidOption, fmt.Sprintf("invalid %s %q", name, v))
+			}
+			flags[name] = on
+		}
+	}
+	if flags["rect"] {
+		opts = append(opts, solver.WithRectangle())
+	}
+	if flags["rotate"] || flags["mirror"] {
+		opts = append(opts, solver.WithRotations(flags["mirror"]))
+	}
+	if flags["poly"] {
+		opts = append(opts, solver.WithPolyominoes())
+	}
+	return opts, nil
+}
+
+// errorResponse is the body of every failed request. Parse errors also
+// give the 1-based line, column and block at fault.
+type errorResponse struct {
+	Error  string `json:"error"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+	Block  int    `json:"block,omitempty"`
+}
+
+// respondError answers with the status for err and its message.
+func respondError(w http.ResponseWriter, err error) {
+	body := errorResponse{Error: err.Error()}
+	var perr *solver.ParseError
+	if errors.As(err, &perr) {
+		body.Line, body.Column, body.Block = perr.Line, perr.Column, perr.Block+1
+	}
+	respond(w, httpStatus(err), body)
+}
+
+// httpStatus maps an error to the status it is answered with, as exitCode
+// does for the command line.
+func httpStatus(err error) int {
+	switch {
+	case errors.Is(err, errTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, errNoJob):
+		return http.StatusNotFound
+	case errors.Is(err, errQueueFull):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, solver.ErrUnreadable), errors.Is(err, solver.ErrInvalidFormat), errors.Is(err, solver.ErrInvalidOption):
+		return http.StatusBadRequest
+	case errors.Is(err, solver.ErrUnsolvable):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, solver.ErrTimeout):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// respond writes v as the JSON body of the response.
+func respond(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
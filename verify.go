@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"tetris_optimizer/internal/solver"
+)
+
+const verifyUsage = "Usage: go run main.go verify [flags] <puzzle|-> <solution|->"
+
+// runVerify checks a solution against its puzzle, printing OK or ERROR
+// followed by every violation, and returns the process exit code.
+func runVerify(args []string) int {
+	flags := newFlagSet("verify", verifyUsage)
+	timeout := flags.Duration("timeout", 0, "give up the -minimal search after this long, e.g. 30s (0 means no limit)")
+	minimal := flags.Bool("minimal", false, "also require the smallest board the solver can find")
+	width := flags.Int("width", 0, "require a board of this width instead of a square")
+	rect := flags.Bool("rect", false, "accept any rectangle instead of a square")
+	rotate := flags.Bool("rotate", false, "accept rotated pieces")
+	mirror := flags.Bool("mirror", false, "accept rotated and flipped pieces")
+	poly := flags.Bool("poly", false, "read pieces of any size from square blocks instead of tetrominoes")
+	sandbox := flags.String("sandbox", "", "only read .txt puzzles from inside this directory, e.g. testfiles")
+	verbose := flags.Bool("verbose", false, "explain errors after the ERROR line")
+	if err := flags.Parse(args); err != nil {
+		return parseExitCode(err)
+	}
+	if flags.NArg() != 2 || flags.Arg(0) == "-" && flags.Arg(1) == "-" {
+		fmt.Fprintln(os.Stderr, verifyUsage)
+		return exitUsage
+	}
+	if err := checkWidth(*width); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+
+	var opts []solver.Option
+	if *width > 0 {
+		opts = append(opts, solver.WithWidth(*width))
+	}
+	if *rect {
+		opts = append(opts, solver.WithRectangle())
+	}
+	if *rotate || *mirror {
+		opts = append(opts, solver.WithRotations(*mirror))
+	}
+	if *poly {
+		opts = append(opts, solver.WithPolyominoes())
+	}
+	if *sandbox != "" {
+		opts = append(opts, solver.WithSandbox(*sandbox))
+	}
+	if *minimal {
+		opts = append(opts, solver.WithMinimalSize())
+	}
+
+	ctx, cancel := timeoutContext(*timeout)
+	defer cancel()
+
+	pieces, err := readPuzzle(flags.Arg(0), opts)
+	if err != nil {
+		return reportError(err, *verbose)
+	}
+	solution, err := readSolution(flags.Arg(1))
+	if err != nil {
+		return reportError(solver.NewValidationError(solver.ErrUnreadable, err.Error()), *verbose)
+	}
+
+	err = solver.VerifyContext(ctx, pieces, solution, opts...)
+	var verr *solver.VerificationError
+	if errors.As(err, &verr) {
+		fmt.Fprintln(os.Stderr, "ERROR")
+		for _, v := range verr.Violations {
+			fmt.Fprintln(os.Stderr, v)
+		}
+		return exitCode(err)
+	}
+	if err != nil {
+		return reportError(err, *verbose)
+	}
+	fmt.Println("OK")
+	return exitOK
+}
+
+// readSolution reads a solved board from name, where "-" means standard
+// input.
+func readSolution(name string) (string, error) {
+	if name == "-" {
+		content, err := io.ReadAll(os.Stdin)
+		return string(content), err
+	}
+	content, err := os.ReadFile(name)
+	return string(content), err
+}